@@ -4,13 +4,11 @@
 package blockstore
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/StirNetwork/chainbridge-core/config"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 type KeyValueReaderWriter interface {
@@ -30,46 +28,26 @@ var (
 	ErrNotFound = errors.New("key not found")
 )
 
-func StoreBlock(db KeyValueWriter, block *big.Int, chainID uint8) error {
-	key := bytes.Buffer{}
-	keyS := fmt.Sprintf("chain:%s:block", string(chainID))
-	key.WriteString(keyS)
-	err := db.SetByKey(key.Bytes(), block.Bytes())
-	if err != nil {
-		return err
+// SetupBlockstore returns block listener starts from. Listener resumes from the block after its last checkpoint if it is
+// greater than startBlock, block stored by previous versions is migrated to a checkpoint first.
+func SetupBlockstore(generalConfig *config.GeneralChainConfig, kvdb KeyValueReaderWriter, startBlock *big.Int, listener string) (*big.Int, error) {
+	if generalConfig.FreshStart {
+		return big.NewInt(0), nil
 	}
-	return nil
-}
-
-func GetLastStoredBlock(db KeyValueReader, chainID uint8) (*big.Int, error) {
-	key := bytes.Buffer{}
-	keyS := fmt.Sprintf("chain:%s:block", string(chainID))
-	key.WriteString(keyS)
-	v, err := db.GetByKey(key.Bytes())
+	err := MigrateLegacyBlock(kvdb, *generalConfig.Id, listener)
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
-			return big.NewInt(0), nil
-		}
-		return nil, err
+		return nil, fmt.Errorf("migrating legacy block: %w", err)
 	}
-	block := big.NewInt(0).SetBytes(v)
-	return block, nil
-}
-
-// SetupBlockstore queries the blockstore for the latest known block. If the latest block is
-// greater than config.StartBlock, then config.StartBlock is replaced with the latest known block.
-func SetupBlockstore(generalConfig *config.GeneralChainConfig, kvdb KeyValueReaderWriter, startBlock *big.Int) (*big.Int, error) {
-	latestBlock, err := GetLastStoredBlock(kvdb, *generalConfig.Id)
+	cp, err := GetCheckpoint(kvdb, *generalConfig.Id, listener)
 	if err != nil {
-		return nil, err
-	}
-
-	if !generalConfig.FreshStart {
-		if latestBlock.Cmp(startBlock) == 1 {
-			return latestBlock, nil
-		} else {
+		if errors.Is(err, ErrNotFound) {
 			return startBlock, nil
 		}
+		return nil, err
+	}
+	next := new(big.Int).Add(cp.Block, big.NewInt(1))
+	if next.Cmp(startBlock) == 1 {
+		return next, nil
 	}
-	return big.NewInt(0), nil
+	return startBlock, nil
 }
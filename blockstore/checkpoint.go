@@ -0,0 +1,171 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// CheckpointHistorySize is the number of last checkpoints kept per listener for reorg rollback
+var CheckpointHistorySize uint64 = 128
+
+// Checkpoint is a block processed by a chain listener
+type Checkpoint struct {
+	Block *big.Int
+	// Hash of the block, empty if listener does not record it
+	Hash      []byte
+	Timestamp time.Time
+}
+
+// storedCheckpoint is numbered so history ring slots left from earlier laps or rolled back checkpoints are told apart
+type storedCheckpoint struct {
+	Seq        uint64
+	Checkpoint *Checkpoint
+}
+
+// StoreCheckpoint makes cp the last processed block of listener on chain and adds it to listener history,
+// overwriting the oldest checkpoint once history holds CheckpointHistorySize checkpoints
+func StoreCheckpoint(db KeyValueReaderWriter, chainID uint8, listener string, cp *Checkpoint) error {
+	seq := uint64(0)
+	current, err := getStoredCheckpoint(db, checkpointKey(chainID, listener))
+	if err == nil {
+		seq = current.Seq + 1
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return storeCheckpoint(db, chainID, listener, &storedCheckpoint{Seq: seq, Checkpoint: cp})
+}
+
+func storeCheckpoint(db KeyValueWriter, chainID uint8, listener string, sc *storedCheckpoint) error {
+	value := bytes.Buffer{}
+	err := gob.NewEncoder(&value).Encode(sc)
+	if err != nil {
+		return err
+	}
+	err = db.SetByKey(historyKey(chainID, listener, sc.Seq%CheckpointHistorySize), value.Bytes())
+	if err != nil {
+		return err
+	}
+	return db.SetByKey(checkpointKey(chainID, listener), value.Bytes())
+}
+
+// GetCheckpoint returns the last block processed by listener on chain or ErrNotFound if listener did not store any
+func GetCheckpoint(db KeyValueReader, chainID uint8, listener string) (*Checkpoint, error) {
+	sc, err := getStoredCheckpoint(db, checkpointKey(chainID, listener))
+	if err != nil {
+		return nil, err
+	}
+	return sc.Checkpoint, nil
+}
+
+// CheckpointHistory returns up to CheckpointHistorySize last checkpoints of listener on chain, oldest first
+func CheckpointHistory(db KeyValueReader, chainID uint8, listener string) ([]*Checkpoint, error) {
+	current, err := getStoredCheckpoint(db, checkpointKey(chainID, listener))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return []*Checkpoint{}, nil
+		}
+		return nil, err
+	}
+	history := []*Checkpoint{current.Checkpoint}
+	for seq := current.Seq; seq > 0 && uint64(len(history)) < CheckpointHistorySize; seq-- {
+		sc, err := getStoredCheckpoint(db, historyKey(chainID, listener, (seq-1)%CheckpointHistorySize))
+		if errors.Is(err, ErrNotFound) || (err == nil && sc.Seq != seq-1) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, sc.Checkpoint)
+	}
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history, nil
+}
+
+// RollbackCheckpoint makes the latest checkpoint at or before block the last processed block of listener on chain.
+// Later checkpoints are dropped from history. Returns ErrNotFound if history has no such checkpoint.
+func RollbackCheckpoint(db KeyValueReaderWriter, chainID uint8, listener string, block *big.Int) (*Checkpoint, error) {
+	current, err := getStoredCheckpoint(db, checkpointKey(chainID, listener))
+	if err != nil {
+		return nil, err
+	}
+	sc := current
+	for sc.Checkpoint.Block.Cmp(block) == 1 {
+		if sc.Seq == 0 {
+			return nil, ErrNotFound
+		}
+		prev, err := getStoredCheckpoint(db, historyKey(chainID, listener, (sc.Seq-1)%CheckpointHistorySize))
+		if err != nil {
+			return nil, err
+		}
+		// Slot was overwritten by a later checkpoint, rollback is deeper than history
+		if prev.Seq != sc.Seq-1 {
+			return nil, ErrNotFound
+		}
+		sc = prev
+	}
+	if sc != current {
+		err = storeCheckpoint(db, chainID, listener, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sc.Checkpoint, nil
+}
+
+// MigrateLegacyBlock stores block written under legacy key by previous versions as the first checkpoint of listener
+// on chain. Nothing is done if listener already has a checkpoint or legacy block was never written.
+func MigrateLegacyBlock(db KeyValueReaderWriter, chainID uint8, listener string) error {
+	_, err := GetCheckpoint(db, chainID, listener)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	v, err := db.GetByKey(legacyBlockKey(chainID))
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return StoreCheckpoint(db, chainID, listener, &Checkpoint{Block: big.NewInt(0).SetBytes(v), Timestamp: time.Now()})
+}
+
+func getStoredCheckpoint(db KeyValueReader, key []byte) (*storedCheckpoint, error) {
+	v, err := db.GetByKey(key)
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	sc := &storedCheckpoint{}
+	err = gob.NewDecoder(bytes.NewReader(v)).Decode(sc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding checkpoint %s: %w", key, err)
+	}
+	return sc, nil
+}
+
+func checkpointKey(chainID uint8, listener string) []byte {
+	return []byte(fmt.Sprintf("chain:%d:listener:%s:checkpoint", chainID, listener))
+}
+
+func historyKey(chainID uint8, listener string, slot uint64) []byte {
+	return []byte(fmt.Sprintf("chain:%d:listener:%s:history:%d", chainID, listener, slot))
+}
+
+// legacyBlockKey is the key previous versions stored last processed block under. Chain ID was converted
+// to a string as a rune, so it is a control character for IDs under 32.
+func legacyBlockKey(chainID uint8) []byte {
+	return []byte(fmt.Sprintf("chain:%s:block", string(rune(chainID))))
+}
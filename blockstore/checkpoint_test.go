@@ -0,0 +1,224 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+type mockKVDB struct {
+	values map[string][]byte
+}
+
+func newMockKVDB() *mockKVDB {
+	return &mockKVDB{values: map[string][]byte{}}
+}
+
+func (db *mockKVDB) GetByKey(key []byte) ([]byte, error) {
+	v, ok := db.values[string(key)]
+	if !ok {
+		return nil, leveldb.ErrNotFound
+	}
+	return v, nil
+}
+
+func (db *mockKVDB) SetByKey(key []byte, value []byte) error {
+	db.values[string(key)] = value
+	return nil
+}
+
+func storeBlocks(t *testing.T, db KeyValueReaderWriter, listener string, from, to int64) {
+	t.Helper()
+	for b := from; b <= to; b++ {
+		err := StoreCheckpoint(db, 1, listener, &Checkpoint{Block: big.NewInt(b), Hash: []byte{byte(b)}, Timestamp: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func expectHistory(t *testing.T, db KeyValueReader, listener string, from, to int64) {
+	t.Helper()
+	history, err := CheckpointHistory(db, 1, listener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(history)) != to-from+1 {
+		t.Fatalf("expected %d checkpoints in history, got %d", to-from+1, len(history))
+	}
+	for i, cp := range history {
+		if cp.Block.Int64() != from+int64(i) {
+			t.Fatalf("expected block %d at position %d of history, got %s", from+int64(i), i, cp.Block)
+		}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	db := newMockKVDB()
+	_, err := GetCheckpoint(db, 1, "deposits")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	expectHistory(t, db, "deposits", 0, -1)
+
+	storeBlocks(t, db, "deposits", 10, 12)
+	cp, err := GetCheckpoint(db, 1, "deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Block.Int64() != 12 || !bytes.Equal(cp.Hash, []byte{12}) || cp.Timestamp.IsZero() {
+		t.Fatalf("unexpected checkpoint %+v", cp)
+	}
+	expectHistory(t, db, "deposits", 10, 12)
+
+	// Listeners of the same chain keep separate checkpoints
+	_, err = GetCheckpoint(db, 1, "events")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCheckpointHistoryIsBounded(t *testing.T) {
+	defer func(size uint64) { CheckpointHistorySize = size }(CheckpointHistorySize)
+	CheckpointHistorySize = 4
+	db := newMockKVDB()
+
+	storeBlocks(t, db, "deposits", 1, 10)
+	expectHistory(t, db, "deposits", 7, 10)
+	if len(db.values) != 5 {
+		t.Fatalf("expected checkpoint and 4 history slots, got %d keys", len(db.values))
+	}
+}
+
+func TestRollbackCheckpoint(t *testing.T) {
+	defer func(size uint64) { CheckpointHistorySize = size }(CheckpointHistorySize)
+	CheckpointHistorySize = 4
+	db := newMockKVDB()
+	storeBlocks(t, db, "deposits", 1, 10)
+
+	// Rolling back to a block without checkpoint picks the closest earlier one
+	storeBlocks(t, db, "deposits", 15, 15)
+	cp, err := RollbackCheckpoint(db, 1, "deposits", big.NewInt(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Block.Int64() != 10 {
+		t.Fatalf("expected rollback to block 10, got %s", cp.Block)
+	}
+	// Slot of block 7 was taken by rolled back block 15
+	expectHistory(t, db, "deposits", 8, 10)
+
+	// Checkpoints stored after rollback replace rolled back ones
+	storeBlocks(t, db, "deposits", 11, 11)
+	expectHistory(t, db, "deposits", 8, 11)
+
+	_, err = RollbackCheckpoint(db, 1, "deposits", big.NewInt(7))
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for rollback deeper than history, got %v", err)
+	}
+	cp, err = GetCheckpoint(db, 1, "deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Block.Int64() != 11 {
+		t.Fatalf("expected failed rollback to keep checkpoint, got block %s", cp.Block)
+	}
+}
+
+func TestMigrateLegacyBlock(t *testing.T) {
+	for _, chainID := range []uint8{1, 31, 32, 200} {
+		db := newMockKVDB()
+		err := db.SetByKey(legacyBlockKey(chainID), big.NewInt(1000).Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = MigrateLegacyBlock(db, chainID, "deposits")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cp, err := GetCheckpoint(db, chainID, "deposits")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cp.Block.Int64() != 1000 {
+			t.Fatalf("chain %d: expected migrated block 1000, got %s", chainID, cp.Block)
+		}
+
+		// Legacy block does not override checkpoints stored later
+		err = StoreCheckpoint(db, chainID, "deposits", &Checkpoint{Block: big.NewInt(1001)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = MigrateLegacyBlock(db, chainID, "deposits")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cp, err = GetCheckpoint(db, chainID, "deposits")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cp.Block.Int64() != 1001 {
+			t.Fatalf("chain %d: expected block 1001, got %s", chainID, cp.Block)
+		}
+	}
+
+	db := newMockKVDB()
+	err := MigrateLegacyBlock(db, 1, "deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.values) != 0 {
+		t.Fatal("expected nothing to be stored without legacy block")
+	}
+}
+
+func TestSetupBlockstore(t *testing.T) {
+	id := uint8(1)
+	db := newMockKVDB()
+	generalConfig := &config.GeneralChainConfig{Id: &id}
+
+	block, err := SetupBlockstore(generalConfig, db, big.NewInt(100), "deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Int64() != 100 {
+		t.Fatalf("expected start block without checkpoint, got %s", block)
+	}
+
+	err = db.SetByKey(legacyBlockKey(id), big.NewInt(150).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err = SetupBlockstore(generalConfig, db, big.NewInt(100), "deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Int64() != 151 {
+		t.Fatalf("expected block after migrated legacy block, got %s", block)
+	}
+
+	block, err = SetupBlockstore(generalConfig, db, big.NewInt(200), "deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Int64() != 200 {
+		t.Fatalf("expected start block greater than checkpoint, got %s", block)
+	}
+
+	generalConfig.FreshStart = true
+	block, err = SetupBlockstore(generalConfig, db, big.NewInt(100), "deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Int64() != 0 {
+		t.Fatalf("expected fresh start from block 0, got %s", block)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/StirNetwork/chainbridge-core/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+// BalanceCheckInterval is how often relayer account balance metric is updated
+var BalanceCheckInterval = time.Minute
+
+type BalanceClient interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	RelayerAddress() common.Address
+}
+
+// monitorBalance records relayer account balance every BalanceCheckInterval until stop is closed
+func monitorBalance(stop <-chan struct{}, client BalanceClient, chainID uint8, stateMetrics *metrics.ChainStateMetrics) {
+	for {
+		balance, err := client.BalanceAt(context.Background(), client.RelayerAddress(), nil)
+		if err != nil {
+			log.Warn().Err(err).Uint8("chainID", chainID).Msg("Unable to get relayer balance")
+		} else {
+			stateMetrics.Balance(chainID, balance)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(BalanceCheckInterval):
+		}
+	}
+}
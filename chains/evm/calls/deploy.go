@@ -5,13 +5,11 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/StirNetwork/chainbridge-core/chains/evm/calls/consts"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/rs/zerolog/log"
 )
 
@@ -53,36 +51,16 @@ func DeployErc20Handler(c ChainClient, txFabric TxFabric, bridgeAddress common.A
 }
 
 func deployContract(client ChainClient, abi abi.ABI, bytecode []byte, txFabric TxFabric, params ...interface{}) (common.Address, error) {
-	gp, err := client.GasPrice()
-	if err != nil {
-		return common.Address{}, err
-	}
-	client.LockNonce()
-	n, err := client.UnsafeNonce()
-	if err != nil {
-		return common.Address{}, err
-	}
 	input, err := abi.Pack("", params...)
 	if err != nil {
 		return common.Address{}, err
 	}
-	tx := txFabric(n.Uint64(), nil, big.NewInt(0), consts.DefaultDeployGasLimit, gp, append(bytecode, input...))
-	hash, err := client.SignAndSendTransaction(context.TODO(), tx)
-	if err != nil {
-		return common.Address{}, err
-	}
-	time.Sleep(2 * time.Second)
-	_, err = client.WaitAndReturnTxReceipt(tx.Hash())
-	if err != nil {
-		return common.Address{}, err
-	}
-	log.Debug().Str("hash", hash.String()).Uint64("nonce", n.Uint64()).Msgf("Contract deployed")
-	address := crypto.CreateAddress(client.From(), n.Uint64())
-	err = client.UnsafeIncreaseNonce()
+	receipt, hash, err := transact(client, txFabric, nil, append(bytecode, input...), consts.DefaultDeployGasLimit)
 	if err != nil {
 		return common.Address{}, err
 	}
-	client.UnlockNonce()
+	address := receipt.ContractAddress
+	log.Debug().Str("hash", hash.String()).Str("address", address.String()).Msgf("Contract deployed")
 	// checks bytecode at address
 	// nil is latest block
 	if code, err := client.CodeAt(context.Background(), address, nil); err != nil {
@@ -156,3 +156,43 @@ func GetERC20Balance(ethClient ChainClient, erc20Addr, address common.Address) (
 	}
 	return balance, nil
 }
+
+func PrepareERC20DecimalsInput() ([]byte, error) {
+	a, err := abi.JSON(strings.NewReader(consts.ERC20PresetMinterPauserABI))
+	if err != nil {
+		return nil, err
+	}
+	return a.Pack("decimals")
+}
+
+func ParseERC20DecimalsOutput(output []byte) (uint8, error) {
+	a, err := abi.JSON(strings.NewReader(consts.ERC20PresetMinterPauserABI))
+	if err != nil {
+		return 0, err
+	}
+	res, err := a.Unpack("decimals", output)
+	if err != nil {
+		return 0, err
+	}
+	return *abi.ConvertType(res[0], new(uint8)).(*uint8), nil
+}
+
+func PrepareResourceIDToTokenAddressInput(rID [32]byte) ([]byte, error) {
+	a, err := abi.JSON(strings.NewReader(consts.ERC20HandlerABI))
+	if err != nil {
+		return nil, err
+	}
+	return a.Pack("_resourceIDToTokenContractAddress", rID)
+}
+
+func ParseResourceIDToTokenAddressOutput(output []byte) (common.Address, error) {
+	a, err := abi.JSON(strings.NewReader(consts.ERC20HandlerABI))
+	if err != nil {
+		return common.Address{}, err
+	}
+	res, err := a.Unpack("_resourceIDToTokenContractAddress", output)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return *abi.ConvertType(res[0], new(common.Address)).(*common.Address), nil
+}
@@ -6,8 +6,10 @@ import (
 	gomath "math"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
+	"github.com/StirNetwork/chainbridge-core/chains/evm/evmtransaction"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -18,17 +20,13 @@ import (
 
 type TxFabric func(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) evmclient.CommonTransaction
 
+// TxTimeout is how long Transact waits for the transaction to be mined
+var TxTimeout = time.Minute * 5
+
 type ChainClient interface {
-	SignAndSendTransaction(ctx context.Context, tx evmclient.CommonTransaction) (common.Hash, error)
+	evmclient.TxClient
 	CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error)
-	WaitAndReturnTxReceipt(h common.Hash) (*types.Receipt, error)
 	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
-	UnsafeNonce() (*big.Int, error)
-	LockNonce()
-	UnlockNonce()
-	UnsafeIncreaseNonce() error
-	GasPrice() (*big.Int, error)
-	From() common.Address
 	Simulate(block *big.Int, txHash common.Hash, fromAddress common.Address) ([]byte, error)
 }
 
@@ -76,33 +74,28 @@ func UserAmountToWei(amount string, decimal *big.Int) (*big.Int, error) {
 	return i, nil
 }
 
+// Transact sends transaction and waits for it to be mined successfully. Transaction is built by txFabric
+// unless client uses dynamic fees for the chain, then EIP-1559 transaction is sent.
 func Transact(client ChainClient, txFabric TxFabric, to *common.Address, data []byte, gasLimit uint64) (common.Hash, error) {
-	gp, err := client.GasPrice()
-	if err != nil {
-		return common.Hash{}, err
-	}
-	client.LockNonce()
-	n, err := client.UnsafeNonce()
-	if err != nil {
-		return common.Hash{}, err
-	}
-	tx := txFabric(n.Uint64(), to, big.NewInt(0), gasLimit, gp, data)
-	_, err = client.SignAndSendTransaction(context.TODO(), tx)
-	if err != nil {
-		return common.Hash{}, err
-	}
+	_, hash, err := transact(client, txFabric, to, data, gasLimit)
+	return hash, err
+}
 
-	log.Debug().Msgf("hash: %v from: %s", tx.Hash(), client.From())
-	_, err = client.WaitAndReturnTxReceipt(tx.Hash())
+func transact(client ChainClient, txFabric TxFabric, to *common.Address, data []byte, gasLimit uint64) (*types.Receipt, common.Hash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), TxTimeout)
+	defer cancel()
+	manager := evmclient.NewTransactionManager(client, evmclient.TxFabric(txFabric), evmtransaction.NewDynamicFeeTransaction, nil)
+	hash, err := manager.Send(ctx, to, data, gasLimit)
 	if err != nil {
-		return common.Hash{}, err
+		return nil, common.Hash{}, err
 	}
-	err = client.UnsafeIncreaseNonce()
+
+	log.Debug().Msgf("hash: %v from: %s", hash, client.From())
+	receipt, err := manager.WaitForReceipt(ctx, hash)
 	if err != nil {
-		return common.Hash{}, err
+		return nil, common.Hash{}, err
 	}
-	client.UnlockNonce()
-	return tx.Hash(), nil
+	return receipt, hash, nil
 }
 
 func ConstructErc20DepositData(destRecipient []byte, amount *big.Int) []byte {
@@ -4,21 +4,32 @@
 package evm
 
 import (
+	"context"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/StirNetwork/chainbridge-core/blockstore"
+	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
+	"github.com/StirNetwork/chainbridge-core/chains/evm/evmtransaction"
+	"github.com/StirNetwork/chainbridge-core/chains/evm/listener"
+	"github.com/StirNetwork/chainbridge-core/chains/evm/voter"
 	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/StirNetwork/chainbridge-core/metrics"
 	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog/log"
 )
 
 type EventListener interface {
-	ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message
+	ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueReaderWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message
+	LastProcessed() (*big.Int, time.Time)
+	// Name identifies checkpoints of listener in blockstore
+	Name() string
 }
 
 type ProposalVoter interface {
-	VoteProposal(message *relayer.Message) error
+	VoteProposal(ctx context.Context, message *relayer.Message) error
 }
 
 // EVMChain is struct that aggregates all data required for
@@ -29,38 +40,100 @@ type EVMChain struct {
 	kvdb                  blockstore.KeyValueReaderWriter
 	bridgeContractAddress string //nolint
 	config                *config.SharedEVMConfig
+	client                ChainClient
+	stateMetrics          *metrics.ChainStateMetrics
+	processors            []relayer.MessageProcessor
 }
 
-func NewEVMChain(dr EventListener, writer ProposalVoter, kvdb blockstore.KeyValueReaderWriter, chainID uint8, config *config.SharedEVMConfig) *EVMChain {
-	return &EVMChain{listener: dr, writer: writer, kvdb: kvdb, chainID: chainID, config: config}
+func NewEVMChain(dr EventListener, writer ProposalVoter, kvdb blockstore.KeyValueReaderWriter, chainID uint8, config *config.SharedEVMConfig, client ChainClient, stateMetrics *metrics.ChainStateMetrics, processors ...relayer.MessageProcessor) *EVMChain {
+	return &EVMChain{listener: dr, writer: writer, kvdb: kvdb, chainID: chainID, config: config, client: client, stateMetrics: stateMetrics, processors: processors}
+}
+
+// SetupDefaultEVMChain builds EVMChain from a raw chain config entry. Listener and voter handlers
+// are registered for every handler contract address set in the config.
+func SetupDefaultEVMChain(rawConfig map[string]interface{}, db blockstore.KeyValueReaderWriter) (*EVMChain, error) {
+	cfg, err := evmclient.NewEVMConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client := evmclient.NewEVMClient()
+	err = client.ConfigurateWithConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedConfig := &cfg.SharedEVMConfig
+	bridgeAddress := common.HexToAddress(sharedConfig.Bridge)
+	eventHandler := listener.NewETHEventHandler(bridgeAddress, client)
+	messageHandler := voter.NewEVMMessageHandler(client, bridgeAddress)
+	if sharedConfig.Erc20Handler != "" {
+		eventHandler.RegisterEventHandler(sharedConfig.Erc20Handler, listener.Erc20EventHandler)
+		messageHandler.RegisterMessageHandler(common.HexToAddress(sharedConfig.Erc20Handler), voter.ERC20MessageHandler)
+	}
+	if sharedConfig.Erc721Handler != "" {
+		eventHandler.RegisterEventHandler(sharedConfig.Erc721Handler, listener.Erc721EventHandler)
+		messageHandler.RegisterMessageHandler(common.HexToAddress(sharedConfig.Erc721Handler), voter.ERC721MessageHandler)
+	}
+	if sharedConfig.GenericHandler != "" {
+		eventHandler.RegisterEventHandler(sharedConfig.GenericHandler, listener.GenericEventHandler)
+		messageHandler.RegisterMessageHandler(common.HexToAddress(sharedConfig.GenericHandler), voter.GenericMessageHandler)
+	}
+
+	processors := []relayer.MessageProcessor{}
+	for _, pc := range sharedConfig.GeneralChainConfig.Processors {
+		p, err := relayer.NewMessageProcessor(pc.Type, pc.Params)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, p)
+	}
+
+	stateMetrics := metrics.NewChainStateMetrics()
+	evmListener := listener.NewEVMListener(client, eventHandler, bridgeAddress, sharedConfig.BlockRange, sharedConfig.BlockConfirmations, sharedConfig.BlockRetryInterval, metrics.NewReorgMetrics(), stateMetrics)
+	evmVoter := voter.NewVoter(messageHandler, client, evmclient.NewTransactionManager(client, evmtransaction.NewTransaction, evmtransaction.NewDynamicFeeTransaction, sharedConfig.MaxGasPrice), sharedConfig.ProposalTimeout, sharedConfig.MaxGasPrice, metrics.NewBridgeMetrics())
+	return NewEVMChain(evmListener, evmVoter, db, *sharedConfig.GeneralChainConfig.Id, sharedConfig, client, stateMetrics, processors...), nil
 }
 
 // PollEvents is the goroutine that polling blocks and searching Deposit Events in them. Event then sent to eventsChan
-func (c *EVMChain) PollEvents(stop <-chan struct{}, sysErr chan<- error, eventsChan chan *relayer.Message) {
+func (c *EVMChain) PollEvents(ctx context.Context, sysErr chan<- error, eventsChan chan *relayer.Message) {
 	log.Info().Msg("Polling Blocks...")
 	// Handler chain specific configs and flags
-	block, err := blockstore.SetupBlockstore(&c.config.GeneralChainConfig, c.kvdb, c.config.StartBlock)
+	block, err := blockstore.SetupBlockstore(&c.config.GeneralChainConfig, c.kvdb, c.config.StartBlock, c.listener.Name())
 	if err != nil {
 		sysErr <- fmt.Errorf("error %w on getting last stored block", err)
 		return
 	}
-	ech := c.listener.ListenToEvents(block, c.chainID, c.kvdb, stop, sysErr)
-	for {
-		select {
-		case <-stop:
-			return
-		case newEvent := <-ech:
-			// Here we can place middlewares for custom logic?
-			eventsChan <- newEvent
-			continue
-		}
+	ech := c.listener.ListenToEvents(block, c.chainID, c.kvdb, ctx.Done(), sysErr)
+	if c.client != nil {
+		go monitorBalance(ctx.Done(), c.client, c.chainID, c.stateMetrics)
+	}
+	// Listener closes ech once it stops after ctx is done, messages it already read are still passed to relayer
+	for newEvent := range ech {
+		// Here we can place middlewares for custom logic?
+		eventsChan <- newEvent
 	}
+	log.Info().Uint8("chainID", c.chainID).Msg("Chain listener stopped")
 }
 
-func (c *EVMChain) Write(msg *relayer.Message) error {
-	return c.writer.VoteProposal(msg)
+// Write votes for proposal of message and executes it, it gives up once ctx is done
+func (c *EVMChain) Write(ctx context.Context, msg *relayer.Message) error {
+	return c.writer.VoteProposal(ctx, msg)
 }
 
 func (c *EVMChain) ChainID() uint8 {
 	return c.chainID
 }
+
+func (c *EVMChain) WorkerConfig() relayer.WorkerConfig {
+	return relayer.WorkerConfig{
+		Concurrency:  c.config.GeneralChainConfig.WorkerConcurrency,
+		QueueSize:    c.config.GeneralChainConfig.WorkerQueueSize,
+		OrderByNonce: c.config.GeneralChainConfig.OrderByNonce,
+	}
+}
+
+// MessageProcessors returns processors of messages written to the chain
+func (c *EVMChain) MessageProcessors() []relayer.MessageProcessor {
+	return c.processors
+}
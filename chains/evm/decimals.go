@@ -0,0 +1,55 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/StirNetwork/chainbridge-core/chains/evm/calls"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenDecimals returns decimals of ERC20 token registered for resourceID in ERC20 handler of the chain.
+// Errors other than failed contract calls wrap relayer.ErrDecimalsUnavailable, retrying does not help with them.
+func (c *EVMChain) TokenDecimals(resourceID [32]byte) (uint64, error) {
+	if c.client == nil {
+		return 0, fmt.Errorf("%w: chain client is not set", relayer.ErrDecimalsUnavailable)
+	}
+	if c.config.Erc20Handler == "" {
+		return 0, fmt.Errorf("%w: ERC20 handler is not configured", relayer.ErrDecimalsUnavailable)
+	}
+	input, err := calls.PrepareResourceIDToTokenAddressInput(resourceID)
+	if err != nil {
+		return 0, err
+	}
+	handler := common.HexToAddress(c.config.Erc20Handler)
+	out, err := c.client.CallContract(context.TODO(), calls.ToCallArg(ethereum.CallMsg{To: &handler, Data: input}), nil)
+	if err != nil {
+		return 0, err
+	}
+	token, err := calls.ParseResourceIDToTokenAddressOutput(out)
+	if err != nil {
+		return 0, fmt.Errorf("%w: token of resource %x: %s", relayer.ErrDecimalsUnavailable, resourceID, err)
+	}
+	if token == (common.Address{}) {
+		return 0, fmt.Errorf("%w: no token registered for resource %x", relayer.ErrDecimalsUnavailable, resourceID)
+	}
+
+	input, err = calls.PrepareERC20DecimalsInput()
+	if err != nil {
+		return 0, err
+	}
+	out, err = c.client.CallContract(context.TODO(), calls.ToCallArg(ethereum.CallMsg{To: &token, Data: input}), nil)
+	if err != nil {
+		return 0, err
+	}
+	decimals, err := calls.ParseERC20DecimalsOutput(out)
+	if err != nil {
+		return 0, fmt.Errorf("%w: decimals of token %s: %s", relayer.ErrDecimalsUnavailable, token.Hex(), err)
+	}
+	return uint64(decimals), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// tokenClient answers handler resource lookups with token address and token decimals calls with decimals
+type tokenClient struct {
+	mockChainClient
+	handler  common.Address
+	token    common.Address
+	decimals uint8
+}
+
+func (c *tokenClient) CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error) {
+	switch *callArgs["to"].(*common.Address) {
+	case c.handler:
+		return common.LeftPadBytes(c.token.Bytes(), 32), nil
+	case c.token:
+		return common.LeftPadBytes([]byte{c.decimals}, 32), nil
+	default:
+		return nil, nil
+	}
+}
+
+func TestTokenDecimals(t *testing.T) {
+	client := &tokenClient{handler: common.HexToAddress("0x3"), token: common.HexToAddress("0x4"), decimals: 6}
+	chain := NewEVMChain(&mockListener{}, nil, nil, 1, &config.SharedEVMConfig{Erc20Handler: "0x3"}, client, nil)
+
+	decimals, err := chain.TokenDecimals([32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decimals != 6 {
+		t.Fatalf("expected 6 decimals, got %d", decimals)
+	}
+
+	client.token = common.Address{}
+	_, err = chain.TokenDecimals([32]byte{1})
+	if !errors.Is(err, relayer.ErrDecimalsUnavailable) {
+		t.Fatalf("expected decimals of resource without token to be unavailable, got %v", err)
+	}
+
+	chain = NewEVMChain(&mockListener{}, nil, nil, 1, &config.SharedEVMConfig{}, client, nil)
+	_, err = chain.TokenDecimals([32]byte{1})
+	if !errors.Is(err, relayer.ErrDecimalsUnavailable) {
+		t.Fatalf("expected decimals to be unavailable without ERC20 handler, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/StirNetwork/chainbridge-core/config"
 	"github.com/StirNetwork/chainbridge-core/crypto/secp256k1"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -49,6 +50,20 @@ func GetConfig(path string, name string) (*RawEVMConfig, error) {
 	return config, nil
 }
 
+// NewEVMConfig decodes, validates and parses a single chain entry of the relayer config
+func NewEVMConfig(chainConfig map[string]interface{}) (*EVMConfig, error) {
+	rawConfig := &RawEVMConfig{}
+	if err := mapstructure.Decode(chainConfig, rawConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode evm chain config, error: %w", err)
+	}
+
+	if err := rawConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	return ParseConfig(rawConfig)
+}
+
 func ParseConfig(rawConfig *RawEVMConfig) (*EVMConfig, error) {
 
 	cfg, err := rawConfig.RawSharedEVMConfig.ParseConfig()
@@ -58,8 +73,8 @@ func ParseConfig(rawConfig *RawEVMConfig) (*EVMConfig, error) {
 
 	config := &EVMConfig{
 		SharedEVMConfig: *cfg,
-		EgsApiKey:       "",
-		EgsSpeed:        "",
+		EgsApiKey:       rawConfig.EgsApiKey,
+		EgsSpeed:        rawConfig.EgsSpeed,
 	}
 
 	return config, nil
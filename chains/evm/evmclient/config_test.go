@@ -7,6 +7,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/StirNetwork/chainbridge-core/config"
 )
@@ -28,6 +29,7 @@ func TestLoadJSONConfig(t *testing.T) {
 func TestParseChainConfig(t *testing.T) {
 
 	generalConfig := createGeneralConfig()
+	confirmations := int64(10)
 
 	input := RawEVMConfig{
 		RawSharedEVMConfig: config.RawSharedEVMConfig{
@@ -36,11 +38,18 @@ func TestParseChainConfig(t *testing.T) {
 			Erc20Handler:       "0x1234",
 			Erc721Handler:      "0x1234",
 			GenericHandler:     "0x1234",
+			GasPricer:          config.PercentileGasPricer,
+			GasPricePercentile: 60,
+			MinGasPrice:        10,
 			MaxGasPrice:        20,
 			GasMultiplier:      1,
 			GasLimit:           10,
 			StartBlock:         9999,
-			BlockConfirmations: 10,
+			BlockConfirmations: &confirmations,
+			BlockRange:         500,
+			BlockRetryInterval: 10,
+			ProposalTimeout:    100,
+			TxType:             config.DynamicFeeTxType,
 		},
 	}
 
@@ -56,11 +65,18 @@ func TestParseChainConfig(t *testing.T) {
 			Erc20Handler:       "0x1234",
 			Erc721Handler:      "0x1234",
 			GenericHandler:     "0x1234",
+			GasPricer:          config.PercentileGasPricer,
+			GasPricePercentile: 60,
+			MinGasPrice:        big.NewInt(10),
 			MaxGasPrice:        big.NewInt(20),
 			GasMultiplier:      big.NewFloat(1),
 			GasLimit:           big.NewInt(10),
 			StartBlock:         big.NewInt(9999),
 			BlockConfirmations: big.NewInt(10),
+			BlockRange:         big.NewInt(500),
+			BlockRetryInterval: time.Second * 10,
+			ProposalTimeout:    big.NewInt(100),
+			TxType:             config.DynamicFeeTxType,
 		},
 	}
 
@@ -100,11 +116,16 @@ func TestParseChainConfigWithNoBlockConfirmations(t *testing.T) {
 			Erc20Handler:       "0x1234",
 			Erc721Handler:      "0x1234",
 			GenericHandler:     "0x1234",
+			GasPricer:          config.NodeGasPricer,
+			GasPricePercentile: 50,
 			MaxGasPrice:        big.NewInt(20),
 			GasMultiplier:      big.NewFloat(1),
 			GasLimit:           big.NewInt(10),
 			StartBlock:         big.NewInt(9999),
 			BlockConfirmations: big.NewInt(10),
+			BlockRange:         big.NewInt(1000),
+			BlockRetryInterval: time.Second * 5,
+			ProposalTimeout:    big.NewInt(50),
 		},
 	}
 
@@ -113,7 +134,41 @@ func TestParseChainConfigWithNoBlockConfirmations(t *testing.T) {
 	}
 }
 
-//TestChainConfigOneContract Tests chain config providing only one contract
+// TestParseChainConfigWithZeroBlockConfirmations Tests that zero block confirmations are not replaced by default
+func TestParseChainConfigWithZeroBlockConfirmations(t *testing.T) {
+	confirmations := int64(0)
+	input := RawEVMConfig{
+		RawSharedEVMConfig: config.RawSharedEVMConfig{
+			GeneralChainConfig: createGeneralConfig(),
+			Bridge:             "0x1234",
+			BlockConfirmations: &confirmations,
+		},
+	}
+
+	out, err := ParseConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.SharedEVMConfig.BlockConfirmations.Int64() != 0 {
+		t.Fatalf("expected 0 block confirmations, got %s", out.SharedEVMConfig.BlockConfirmations)
+	}
+
+	// Decoded from chain config entry
+	out, err = NewEVMConfig(map[string]interface{}{"name": "chain", "id": 1, "endpoint": "endpoint", "from": "0x0", "bridge": "0x1234", "blockConfirmations": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.SharedEVMConfig.BlockConfirmations.Int64() != 0 {
+		t.Fatalf("expected 0 block confirmations, got %s", out.SharedEVMConfig.BlockConfirmations)
+	}
+
+	confirmations = -1
+	if err = input.Validate(); err == nil {
+		t.Error("config has negative blockConfirmations but no error reported")
+	}
+}
+
+// TestChainConfigOneContract Tests chain config providing only one contract
 func TestChainConfigOneContract(t *testing.T) {
 
 	generalConfig := createGeneralConfig()
@@ -140,11 +195,16 @@ func TestChainConfigOneContract(t *testing.T) {
 			GeneralChainConfig: generalConfig,
 			Bridge:             "0x1234",
 			Erc20Handler:       "0x1234",
+			GasPricer:          config.NodeGasPricer,
+			GasPricePercentile: 50,
 			MaxGasPrice:        big.NewInt(20),
 			GasMultiplier:      big.NewFloat(1),
 			GasLimit:           big.NewInt(10),
 			StartBlock:         big.NewInt(0),
 			BlockConfirmations: big.NewInt(10),
+			BlockRange:         big.NewInt(1000),
+			BlockRetryInterval: time.Second * 5,
+			ProposalTimeout:    big.NewInt(50),
 		},
 	}
 
@@ -174,6 +234,22 @@ func TestRequiredOpts(t *testing.T) {
 
 }
 
+func TestUnknownTxType(t *testing.T) {
+	input := RawEVMConfig{
+		RawSharedEVMConfig: config.RawSharedEVMConfig{
+			GeneralChainConfig: createGeneralConfig(),
+			Bridge:             "0x1234",
+			TxType:             "eip2930",
+		},
+	}
+
+	err := input.Validate()
+
+	if err == nil {
+		t.Error("config has unknown txType but no error reported")
+	}
+}
+
 func createGeneralConfig() config.GeneralChainConfig {
 	var id uint8 = 1
 	return config.GeneralChainConfig{
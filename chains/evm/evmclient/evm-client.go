@@ -27,11 +27,12 @@ import (
 
 type EVMClient struct {
 	*ethclient.Client
-	rpClient  *rpc.Client
-	nonceLock sync.Mutex
-	config    *EVMConfig
-	nonce     *big.Int
-	gasPrice  *big.Int
+	rpClient    *rpc.Client
+	config      *EVMConfig
+	gasPrice    *big.Int
+	gasPricer   GasPricer
+	feesLock    sync.Mutex
+	dynamicFees *bool // cached result of head block baseFee check
 }
 
 type CommonTransaction interface {
@@ -70,12 +71,17 @@ func (c *EVMClient) Configurate(path string, name string) error {
 	if err != nil {
 		return err
 	}
+	return c.ConfigurateWithConfig(cfg)
+}
+
+// ConfigurateWithConfig loads the relayer keypair and connects the client to the chain described by already parsed config
+func (c *EVMClient) ConfigurateWithConfig(cfg *EVMConfig) error {
 	c.config = cfg
 	generalConfig := cfg.SharedEVMConfig.GeneralChainConfig
 
 	kp, err := keystore.KeypairFromAddress(generalConfig.From, keystore.EthChain, generalConfig.KeystorePath, generalConfig.Insecure)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	krp := kp.(*secp256k1.Keypair)
 	c.config.kp = krp
@@ -88,6 +94,12 @@ func (c *EVMClient) Configurate(path string, name string) error {
 	c.Client = ethclient.NewClient(rpcClient)
 	c.rpClient = rpcClient
 
+	gasPricer, err := NewGasPricer(c, cfg)
+	if err != nil {
+		return err
+	}
+	c.gasPricer = gasPricer
+
 	if generalConfig.LatestBlock {
 		curr, err := c.LatestBlock()
 		if err != nil {
@@ -121,8 +133,13 @@ func (h *headerNumber) UnmarshalJSON(input []byte) error {
 
 // LatestBlock returns the latest block from the current chain
 func (c *EVMClient) LatestBlock() (*big.Int, error) {
+	return c.LatestBlockContext(context.Background())
+}
+
+// LatestBlockContext returns the latest block from the current chain, it gives up once ctx is done
+func (c *EVMClient) LatestBlockContext(ctx context.Context) (*big.Int, error) {
 	var head *headerNumber
-	err := c.rpClient.CallContext(context.Background(), &head, "eth_getBlockByNumber", toBlockNumArg(nil), false)
+	err := c.rpClient.CallContext(ctx, &head, "eth_getBlockByNumber", toBlockNumArg(nil), false)
 	if err == nil && head == nil {
 		err = ethereum.NotFound
 	}
@@ -229,42 +246,13 @@ func (c *EVMClient) SignAndSendTransaction(ctx context.Context, tx CommonTransac
 	return tx.Hash(), nil
 }
 
-func (c *EVMClient) RelayerAddress() common.Address {
-	return c.config.kp.CommonAddress()
-}
-
-func (c *EVMClient) LockNonce() {
-	c.nonceLock.Lock()
-}
-
-func (c *EVMClient) UnlockNonce() {
-	c.nonceLock.Unlock()
+// KeypairLoaded reports if relayer keypair was loaded from keystore
+func (c *EVMClient) KeypairLoaded() bool {
+	return c.config != nil && c.config.kp != nil
 }
 
-func (c *EVMClient) UnsafeNonce() (*big.Int, error) {
-	var err error
-	for i := 0; i <= 10; i++ {
-		if c.nonce == nil {
-			nonce, err := c.PendingNonceAt(context.Background(), c.config.kp.CommonAddress())
-			if err != nil {
-				time.Sleep(1 * time.Second)
-				continue
-			}
-			c.nonce = big.NewInt(0).SetUint64(nonce)
-			return c.nonce, nil
-		}
-		return c.nonce, nil
-	}
-	return nil, err
-}
-
-func (c *EVMClient) UnsafeIncreaseNonce() error {
-	nonce, err := c.UnsafeNonce()
-	if err != nil {
-		return err
-	}
-	c.nonce = nonce.Add(nonce, big.NewInt(1))
-	return nil
+func (c *EVMClient) RelayerAddress() common.Address {
+	return c.config.kp.CommonAddress()
 }
 
 func (c *EVMClient) GasPrice() (*big.Int, error) {
@@ -278,22 +266,18 @@ func (c *EVMClient) GasPrice() (*big.Int, error) {
 	return gasPrice, nil
 }
 
+// SafeEstimateGas returns gas price of the chain gas pricer, if client has no gas pricer node suggested gas price
+// multiplied by GasMultiplier is used. Gas price never exceeds MaxGasPrice from config.
 func (c *EVMClient) SafeEstimateGas(ctx context.Context) (*big.Int, error) {
-	suggestedGasPrice, err := c.SuggestGasPrice(context.TODO())
+	pricer := c.gasPricer
+	if pricer == nil {
+		pricer = NewClampedGasPricer(NewNodeGasPricer(c, c.config.SharedEVMConfig.GasMultiplier), nil, c.config.SharedEVMConfig.MaxGasPrice)
+	}
+	gasPrice, err := pricer.GasPrice(ctx)
 	if err != nil {
 		return nil, err
 	}
-	log.Debug().Msgf("Suggested GP %s", suggestedGasPrice.String())
-	var gasPrice *big.Int
-	if c.config.SharedEVMConfig.GasMultiplier != nil {
-		gasPrice = multiplyGasPrice(suggestedGasPrice, c.config.SharedEVMConfig.GasMultiplier)
-	}
-	// Check we aren't exceeding our limit
-	if c.config.SharedEVMConfig.MaxGasPrice != nil {
-		if gasPrice.Cmp(c.config.SharedEVMConfig.MaxGasPrice) == 1 {
-			return c.config.SharedEVMConfig.MaxGasPrice, nil
-		}
-	}
+	log.Debug().Msgf("Estimated GP %s", gasPrice.String())
 	return gasPrice, nil
 }
 
@@ -0,0 +1,99 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evmclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// FeeHistoryBlocks is the number of recent blocks used to estimate priority fee of dynamic fee transaction
+var FeeHistoryBlocks = 10
+
+// FeeHistoryRewardPercentile is the percentile of priority fees paid in a block that is taken as the fee paid in that block
+var FeeHistoryRewardPercentile float64 = 50
+
+type feeHistory struct {
+	OldestBlock *hexutil.Big     `json:"oldestBlock"`
+	Reward      [][]*hexutil.Big `json:"reward"`
+	BaseFee     []*hexutil.Big   `json:"baseFeePerGas"`
+}
+
+// UseDynamicFees reports whether EIP-1559 transactions are sent to the chain. Unless transaction type is set in config
+// it is decided once by presence of baseFee in the head block.
+func (c *EVMClient) UseDynamicFees(ctx context.Context) (bool, error) {
+	switch c.config.SharedEVMConfig.TxType {
+	case config.LegacyTxType:
+		return false, nil
+	case config.DynamicFeeTxType:
+		return true, nil
+	}
+
+	c.feesLock.Lock()
+	defer c.feesLock.Unlock()
+	if c.dynamicFees != nil {
+		return *c.dynamicFees, nil
+	}
+	head, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	dynamicFees := head.BaseFee != nil
+	c.dynamicFees = &dynamicFees
+	return dynamicFees, nil
+}
+
+// GasFees returns priority fee and fee cap of dynamic fee transaction derived from eth_feeHistory. Priority fee is the median
+// of fees paid in recent blocks, fee cap allows next block base fee to double and never exceeds MaxGasPrice from config.
+func (c *EVMClient) GasFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	var history feeHistory
+	err := c.rpClient.CallContext(ctx, &history, "eth_feeHistory", hexutil.Uint(FeeHistoryBlocks), "latest", []float64{FeeHistoryRewardPercentile})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, nil, errors.New("fee history has no base fee")
+	}
+	// The last base fee is the base fee of the next block
+	baseFee := (*big.Int)(history.BaseFee[len(history.BaseFee)-1])
+
+	gasTipCap := medianReward(history.Reward)
+	if gasTipCap == nil {
+		gasTipCap, err = c.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	gasFeeCap := new(big.Int).Mul(baseFee, big.NewInt(2))
+	gasFeeCap.Add(gasFeeCap, gasTipCap)
+	maxGasPrice := c.config.SharedEVMConfig.MaxGasPrice
+	if maxGasPrice != nil && gasFeeCap.Cmp(maxGasPrice) == 1 {
+		gasFeeCap = new(big.Int).Set(maxGasPrice)
+	}
+	if gasTipCap.Cmp(gasFeeCap) == 1 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return gasTipCap, gasFeeCap, nil
+}
+
+// medianReward returns median of the first reward percentile of every block or nil if there are no rewards
+func medianReward(rewards [][]*hexutil.Big) *big.Int {
+	values := make([]*big.Int, 0, len(rewards))
+	for _, r := range rewards {
+		if len(r) > 0 && r[0] != nil {
+			values = append(values, (*big.Int)(r[0]))
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) == -1 })
+	return new(big.Int).Set(values[len(values)/2])
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evmclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type mockFeeHistoryService struct {
+	history *feeHistory
+}
+
+func (s *mockFeeHistoryService) FeeHistory(blocks hexutil.Uint, newestBlock string, percentiles []float64) *feeHistory {
+	return s.history
+}
+
+func newFeeHistoryClient(t *testing.T, history *feeHistory, maxGasPrice *big.Int) *EVMClient {
+	server := rpc.NewServer()
+	err := server.RegisterName("eth", &mockFeeHistoryService{history: history})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := rpc.DialInProc(server)
+	return &EVMClient{
+		Client:   ethclient.NewClient(rpcClient),
+		rpClient: rpcClient,
+		config:   &EVMConfig{SharedEVMConfig: config.SharedEVMConfig{MaxGasPrice: maxGasPrice}},
+	}
+}
+
+func hexBigs(values ...int64) []*hexutil.Big {
+	res := make([]*hexutil.Big, len(values))
+	for i, v := range values {
+		res[i] = (*hexutil.Big)(big.NewInt(v))
+	}
+	return res
+}
+
+func TestGasFeesFromFeeHistory(t *testing.T) {
+	history := &feeHistory{
+		OldestBlock: (*hexutil.Big)(big.NewInt(1)),
+		Reward:      [][]*hexutil.Big{hexBigs(5), hexBigs(100), hexBigs(7)},
+		BaseFee:     hexBigs(40, 45, 50, 60),
+	}
+	c := newFeeHistoryClient(t, history, big.NewInt(1000))
+
+	gasTipCap, gasFeeCap, err := c.GasFees(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Median tip of 5, 100 and 7 plus twice the next block base fee
+	if gasTipCap.Int64() != 7 || gasFeeCap.Int64() != 127 {
+		t.Fatalf("expected tip 7 and fee cap 127, got %s and %s", gasTipCap, gasFeeCap)
+	}
+}
+
+func TestGasFeesRespectMaxGasPrice(t *testing.T) {
+	history := &feeHistory{
+		OldestBlock: (*hexutil.Big)(big.NewInt(1)),
+		Reward:      [][]*hexutil.Big{hexBigs(80)},
+		BaseFee:     hexBigs(40, 60),
+	}
+	c := newFeeHistoryClient(t, history, big.NewInt(50))
+
+	gasTipCap, gasFeeCap, err := c.GasFees(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gasTipCap.Int64() != 50 || gasFeeCap.Int64() != 50 {
+		t.Fatalf("expected tip and fee cap capped at 50, got %s and %s", gasTipCap, gasFeeCap)
+	}
+}
+
+func TestUseDynamicFeesFromConfig(t *testing.T) {
+	c := newFeeHistoryClient(t, nil, nil)
+
+	c.config.SharedEVMConfig.TxType = config.LegacyTxType
+	dynamicFees, err := c.UseDynamicFees(context.Background())
+	if err != nil || dynamicFees {
+		t.Fatalf("expected legacy transactions, got %v, %v", dynamicFees, err)
+	}
+	c.config.SharedEVMConfig.TxType = config.DynamicFeeTxType
+	dynamicFees, err = c.UseDynamicFees(context.Background())
+	if err != nil || !dynamicFees {
+		t.Fatalf("expected dynamic fee transactions, got %v, %v", dynamicFees, err)
+	}
+}
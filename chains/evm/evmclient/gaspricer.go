@@ -0,0 +1,228 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evmclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// PercentileGasPricerBlocks is the number of recent blocks PercentileGasPricer takes gas prices from
+var PercentileGasPricerBlocks = 20
+
+// EthGasStationURL is the endpoint EthGasStationGasPricer queries gas prices from
+var EthGasStationURL = "https://ethgasstation.info/api/ethgasAPI.json"
+
+// EthGasStationTimeout is the timeout of ethgasstation HTTP request
+var EthGasStationTimeout = time.Second * 10
+
+type GasPricer interface {
+	GasPrice(ctx context.Context) (*big.Int, error)
+}
+
+type GasPricerClient interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// NewGasPricer creates gas pricer selected in chain config. Gas price is clamped to configured min and max gas price
+func NewGasPricer(client GasPricerClient, cfg *EVMConfig) (GasPricer, error) {
+	sharedConfig := cfg.SharedEVMConfig
+	var pricer GasPricer
+	switch sharedConfig.GasPricer {
+	case config.StaticGasPricer:
+		pricer = NewStaticGasPricer(sharedConfig.GasPrice)
+	case config.NodeGasPricer, "":
+		pricer = NewNodeGasPricer(client, sharedConfig.GasMultiplier)
+	case config.PercentileGasPricer:
+		pricer = NewPercentileGasPricer(client, PercentileGasPricerBlocks, sharedConfig.GasPricePercentile)
+	case config.EthGasStationGasPricer:
+		pricer = NewEthGasStationGasPricer(EthGasStationURL, cfg.EgsApiKey, cfg.EgsSpeed)
+	default:
+		return nil, fmt.Errorf("unknown gas pricer %s", sharedConfig.GasPricer)
+	}
+	return NewClampedGasPricer(pricer, sharedConfig.MinGasPrice, sharedConfig.MaxGasPrice), nil
+}
+
+// StaticGasPricer always returns the same gas price
+type StaticGasPricer struct {
+	gasPrice *big.Int
+}
+
+func NewStaticGasPricer(gasPrice *big.Int) *StaticGasPricer {
+	return &StaticGasPricer{gasPrice: gasPrice}
+}
+
+func (p *StaticGasPricer) GasPrice(ctx context.Context) (*big.Int, error) {
+	if p.gasPrice == nil {
+		return nil, errors.New("static gas price is not set")
+	}
+	return new(big.Int).Set(p.gasPrice), nil
+}
+
+// NodeGasPricer returns gas price suggested by the node multiplied by multiplier
+type NodeGasPricer struct {
+	client     GasPricerClient
+	multiplier *big.Float
+}
+
+func NewNodeGasPricer(client GasPricerClient, multiplier *big.Float) *NodeGasPricer {
+	return &NodeGasPricer{client: client, multiplier: multiplier}
+}
+
+func (p *NodeGasPricer) GasPrice(ctx context.Context) (*big.Int, error) {
+	suggestedGasPrice, err := p.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.multiplier == nil {
+		return suggestedGasPrice, nil
+	}
+	return multiplyGasPrice(suggestedGasPrice, p.multiplier), nil
+}
+
+// PercentileGasPricer returns percentile of gas prices paid by transactions in recent blocks.
+// Gas price is recalculated only when a new block is produced.
+type PercentileGasPricer struct {
+	client     GasPricerClient
+	blocks     int
+	percentile int
+	lock       sync.Mutex
+	head       *big.Int
+	gasPrice   *big.Int
+}
+
+func NewPercentileGasPricer(client GasPricerClient, blocks int, percentile int) *PercentileGasPricer {
+	return &PercentileGasPricer{client: client, blocks: blocks, percentile: percentile}
+}
+
+func (p *PercentileGasPricer) GasPrice(ctx context.Context) (*big.Int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	head, err := p.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.head != nil && p.head.Cmp(head.Number) == 0 {
+		return new(big.Int).Set(p.gasPrice), nil
+	}
+
+	prices := make([]*big.Int, 0)
+	for i := 0; i < p.blocks && head.Number.Int64()-int64(i) >= 0; i++ {
+		block, err := p.client.BlockByNumber(ctx, new(big.Int).Sub(head.Number, big.NewInt(int64(i))))
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range block.Transactions() {
+			prices = append(prices, effectiveGasPrice(tx, block.BaseFee()))
+		}
+	}
+	if len(prices) == 0 {
+		return nil, errors.New("no transactions in recent blocks")
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) == -1 })
+	p.head = head.Number
+	p.gasPrice = prices[(len(prices)-1)*p.percentile/100]
+	return new(big.Int).Set(p.gasPrice), nil
+}
+
+// effectiveGasPrice returns gas price actually paid by transaction, for dynamic fee transaction it is base fee plus paid tip
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return tx.GasPrice()
+	}
+	tip, err := tx.EffectiveGasTip(baseFee)
+	if err != nil {
+		return tx.GasPrice()
+	}
+	return tip.Add(tip, baseFee)
+}
+
+// EthGasStationGasPricer returns gas price for speed (average, fast or fastest) from ethgasstation-style HTTP oracle
+type EthGasStationGasPricer struct {
+	url        string
+	apiKey     string
+	speed      string
+	httpClient *http.Client
+}
+
+func NewEthGasStationGasPricer(url string, apiKey string, speed string) *EthGasStationGasPricer {
+	if speed == "" {
+		speed = "fast"
+	}
+	return &EthGasStationGasPricer{url: url, apiKey: apiKey, speed: speed, httpClient: &http.Client{Timeout: EthGasStationTimeout}}
+}
+
+func (p *EthGasStationGasPricer) GasPrice(ctx context.Context) (*big.Int, error) {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		q := u.Query()
+		q.Set("api-key", p.apiKey)
+		u.RawQuery = q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ethgasstation responded with status %s", resp.Status)
+	}
+	// Prices are returned in tenths of gwei
+	var prices map[string]float64
+	err = json.NewDecoder(resp.Body).Decode(&prices)
+	if err != nil {
+		return nil, err
+	}
+	price, ok := prices[p.speed]
+	if !ok {
+		return nil, fmt.Errorf("ethgasstation response has no %s gas price", p.speed)
+	}
+	gasPrice, _ := new(big.Float).Mul(big.NewFloat(price), big.NewFloat(1e8)).Int(nil)
+	return gasPrice, nil
+}
+
+// ClampedGasPricer keeps gas price of the wrapped gas pricer between min and max gas price, nil bound is not applied
+type ClampedGasPricer struct {
+	pricer      GasPricer
+	minGasPrice *big.Int
+	maxGasPrice *big.Int
+}
+
+func NewClampedGasPricer(pricer GasPricer, minGasPrice *big.Int, maxGasPrice *big.Int) *ClampedGasPricer {
+	return &ClampedGasPricer{pricer: pricer, minGasPrice: minGasPrice, maxGasPrice: maxGasPrice}
+}
+
+func (p *ClampedGasPricer) GasPrice(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := p.pricer.GasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.minGasPrice != nil && gasPrice.Cmp(p.minGasPrice) == -1 {
+		return new(big.Int).Set(p.minGasPrice), nil
+	}
+	if p.maxGasPrice != nil && gasPrice.Cmp(p.maxGasPrice) == 1 {
+		return new(big.Int).Set(p.maxGasPrice), nil
+	}
+	return gasPrice, nil
+}
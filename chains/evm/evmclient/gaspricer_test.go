@@ -0,0 +1,174 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evmclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type mockGasPricerClient struct {
+	suggested *big.Int
+	blocks    []*types.Block
+	fetched   int
+}
+
+func (c *mockGasPricerClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return c.suggested, nil
+}
+
+func (c *mockGasPricerClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.blocks[len(c.blocks)-1].Header(), nil
+}
+
+func (c *mockGasPricerClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	c.fetched++
+	return c.blocks[number.Int64()], nil
+}
+
+func newTestBlock(number int64, gasPrices ...int64) *types.Block {
+	txs := make([]*types.Transaction, len(gasPrices))
+	for i, gp := range gasPrices {
+		txs[i] = types.NewTransaction(uint64(i), common.Address{}, big.NewInt(0), 21000, big.NewInt(gp), nil)
+	}
+	return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(number)}).WithBody(txs, nil)
+}
+
+func TestStaticGasPricer(t *testing.T) {
+	gp, err := NewStaticGasPricer(big.NewInt(42)).GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp.Int64() != 42 {
+		t.Fatalf("expected gas price 42, got %s", gp)
+	}
+}
+
+func TestNodeGasPricer(t *testing.T) {
+	client := &mockGasPricerClient{suggested: big.NewInt(100)}
+
+	gp, err := NewNodeGasPricer(client, nil).GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp.Int64() != 100 {
+		t.Fatalf("expected gas price 100 without multiplier, got %s", gp)
+	}
+	gp, err = NewNodeGasPricer(client, big.NewFloat(1.5)).GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp.Int64() != 150 {
+		t.Fatalf("expected gas price 150, got %s", gp)
+	}
+}
+
+func TestPercentileGasPricer(t *testing.T) {
+	client := &mockGasPricerClient{blocks: []*types.Block{
+		newTestBlock(0, 1000),
+		newTestBlock(1, 10, 20, 30),
+		newTestBlock(2, 40, 50),
+	}}
+	pricer := NewPercentileGasPricer(client, 2, 50)
+
+	gp, err := pricer.GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Median of prices from the last 2 blocks
+	if gp.Int64() != 30 {
+		t.Fatalf("expected gas price 30, got %s", gp)
+	}
+	_, err = pricer.GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.fetched != 2 {
+		t.Fatalf("expected blocks to be fetched once per head, fetched %d blocks", client.fetched)
+	}
+}
+
+func TestEthGasStationGasPricer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"average": 100, "fast": 150, "fastest": 200, "safeLow": 90}`)
+	}))
+	defer server.Close()
+
+	gp, err := NewEthGasStationGasPricer(server.URL, "key", "").GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 150 tenths of gwei
+	if gp.Int64() != 15000000000 {
+		t.Fatalf("expected gas price 15 gwei, got %s", gp)
+	}
+	_, err = NewEthGasStationGasPricer(server.URL, "", "fastest").GasPrice(context.Background())
+	if err == nil {
+		t.Fatal("expected error for unauthorized request")
+	}
+}
+
+func TestClampedGasPricer(t *testing.T) {
+	pricer := NewClampedGasPricer(NewStaticGasPricer(big.NewInt(5)), big.NewInt(10), big.NewInt(20))
+	gp, err := pricer.GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp.Int64() != 10 {
+		t.Fatalf("expected min gas price 10, got %s", gp)
+	}
+
+	pricer = NewClampedGasPricer(NewStaticGasPricer(big.NewInt(25)), big.NewInt(10), big.NewInt(20))
+	gp, err = pricer.GasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp.Int64() != 20 {
+		t.Fatalf("expected max gas price 20, got %s", gp)
+	}
+}
+
+type mockGasPriceService struct{}
+
+func (s *mockGasPriceService) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(30))
+}
+
+// TestSafeEstimateGasWithoutMultiplier checks that client without configured gas pricer and multiplier uses suggested gas price
+func TestSafeEstimateGasWithoutMultiplier(t *testing.T) {
+	server := rpc.NewServer()
+	err := server.RegisterName("eth", &mockGasPriceService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := rpc.DialInProc(server)
+	c := &EVMClient{
+		Client:   ethclient.NewClient(rpcClient),
+		rpClient: rpcClient,
+		config:   &EVMConfig{SharedEVMConfig: config.SharedEVMConfig{MaxGasPrice: big.NewInt(20)}},
+	}
+
+	gp, err := c.SafeEstimateGas(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp.Int64() != 20 {
+		t.Fatalf("expected gas price capped at 20, got %s", gp)
+	}
+}
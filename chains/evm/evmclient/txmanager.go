@@ -0,0 +1,346 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evmclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// StuckTxTimeout is how long transaction can stay unmined before it is replaced with a higher gas price
+var StuckTxTimeout = time.Minute * 3
+
+// ReplacementGasPriceBumpPercent is gas price increase of replacement transaction, nodes require at least 10%
+var ReplacementGasPriceBumpPercent int64 = 20
+
+// TxPollInterval is how often WaitForReceipt checks for transaction receipt
+var TxPollInterval = time.Second * 5
+
+// NonceResyncLimit is how many times transaction is resent with resynced nonce after "nonce too low" error
+var NonceResyncLimit = 3
+
+type TxFabric func(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) CommonTransaction
+
+type DynamicFeeTxFabric func(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasTipCap *big.Int, gasFeeCap *big.Int, data []byte) CommonTransaction
+
+type TxClient interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SignAndSendTransaction(ctx context.Context, tx CommonTransaction) (common.Hash, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	GasPrice() (*big.Int, error)
+	UseDynamicFees(ctx context.Context) (bool, error)
+	GasFees(ctx context.Context) (*big.Int, *big.Int, error)
+	From() common.Address
+}
+
+type pendingTx struct {
+	nonce     uint64
+	to        *common.Address
+	data      []byte
+	gasLimit  uint64
+	gasPrice  *big.Int      // gas price of legacy transaction or fee cap of dynamic fee transaction
+	gasTipCap *big.Int      // nil for legacy transaction
+	hashes    []common.Hash // original transaction hash followed by hashes of its replacements
+	sentAt    time.Time
+	replacing bool // replacement is being sent
+}
+
+// Fees are fees of a transaction
+type Fees struct {
+	GasTipCap *big.Int // priority fee of dynamic fee transaction, nil for legacy transaction
+	GasPrice  *big.Int // gas price of legacy transaction or fee cap of dynamic fee transaction
+}
+
+// TransactionManager owns nonce assignment of the relayer account. It tracks sent transactions and replaces
+// the ones that are not mined for StuckTxTimeout with a higher gas price.
+type TransactionManager struct {
+	client        TxClient
+	fabric        TxFabric
+	dynamicFabric DynamicFeeTxFabric
+	maxGasPrice   *big.Int
+	nonceLock     sync.Mutex // serializes nonce assignment and sending of new transactions
+	nonce         *big.Int   // next nonce to use, nil if it has to be synced from the node
+	lock          sync.Mutex // guards pending and nonceStale, it is not held during RPC calls
+	pending       map[common.Hash]*pendingTx
+	nonceStale    bool // nonce is resynced from the node before the next transaction
+}
+
+// NewTransactionManager creates manager sending transactions built by fabric. If dynamicFabric is not nil
+// EIP-1559 transactions are sent to chains the client uses dynamic fees for. Fees of replacement transactions
+// never exceed maxGasPrice, nil maxGasPrice is not applied.
+func NewTransactionManager(client TxClient, fabric TxFabric, dynamicFabric DynamicFeeTxFabric, maxGasPrice *big.Int) *TransactionManager {
+	return &TransactionManager{
+		client:        client,
+		fabric:        fabric,
+		dynamicFabric: dynamicFabric,
+		maxGasPrice:   maxGasPrice,
+		pending:       make(map[common.Hash]*pendingTx),
+	}
+}
+
+// Send sends transaction with fees suggested by the client
+func (m *TransactionManager) Send(ctx context.Context, to *common.Address, data []byte, gasLimit uint64) (common.Hash, error) {
+	return m.SendWithGasPrice(ctx, to, data, gasLimit, nil)
+}
+
+// SendWithGasPrice sends transaction with provided gas price, if gasPrice is nil price suggested by the client is used.
+// For dynamic fee transaction gasPrice is used as fee cap.
+func (m *TransactionManager) SendWithGasPrice(ctx context.Context, to *common.Address, data []byte, gasLimit uint64, gasPrice *big.Int) (common.Hash, error) {
+	gasTipCap, gasPrice, err := m.fees(ctx, gasPrice)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return m.send(ctx, to, data, gasLimit, gasTipCap, gasPrice)
+}
+
+// SendWithFees sends transaction with provided fees, if fees are nil fees suggested by the client are used.
+// Transaction is sent as legacy one if fees have no priority fee or manager has no dynamic fee fabric.
+func (m *TransactionManager) SendWithFees(ctx context.Context, to *common.Address, data []byte, gasLimit uint64, fees *Fees) (common.Hash, error) {
+	if fees == nil {
+		return m.Send(ctx, to, data, gasLimit)
+	}
+	gasTipCap := fees.GasTipCap
+	if m.dynamicFabric == nil {
+		gasTipCap = nil
+	}
+	return m.send(ctx, to, data, gasLimit, gasTipCap, fees.GasPrice)
+}
+
+// SuggestFees returns fees of the next transaction suggested by the client, priority fee and fee cap are derived from
+// fee history on chains the client uses dynamic fees for
+func (m *TransactionManager) SuggestFees(ctx context.Context) (*Fees, error) {
+	gasTipCap, gasPrice, err := m.fees(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Fees{GasTipCap: gasTipCap, GasPrice: gasPrice}, nil
+}
+
+// send sends transaction with the next nonce. Nonce is resynced from the node and transaction is resent
+// if node reports that nonce is too low. Nonce is also resynced once a transaction is forgotten before it is mined
+// or no sent transactions are pending anymore.
+func (m *TransactionManager) send(ctx context.Context, to *common.Address, data []byte, gasLimit uint64, gasTipCap *big.Int, gasPrice *big.Int) (common.Hash, error) {
+	m.nonceLock.Lock()
+	defer m.nonceLock.Unlock()
+	for i := 0; ; i++ {
+		nonce, err := m.nextNonce(ctx)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		hash, err := m.client.SignAndSendTransaction(ctx, m.newTx(nonce, to, gasLimit, gasTipCap, gasPrice, data))
+		if err != nil {
+			if isNonceTooLowError(err) && i < NonceResyncLimit {
+				log.Warn().Err(err).Uint64("nonce", nonce).Msg("Nonce too low, resyncing nonce")
+				m.nonce = nil
+				continue
+			}
+			return common.Hash{}, err
+		}
+		log.Debug().Str("hash", hash.String()).Uint64("nonce", nonce).Msg("Transaction sent")
+		m.nonce.Add(m.nonce, big.NewInt(1))
+		m.lock.Lock()
+		m.pending[hash] = &pendingTx{
+			nonce:     nonce,
+			to:        to,
+			data:      data,
+			gasLimit:  gasLimit,
+			gasPrice:  gasPrice,
+			gasTipCap: gasTipCap,
+			hashes:    []common.Hash{hash},
+			sentAt:    time.Now(),
+		}
+		m.lock.Unlock()
+		return hash, nil
+	}
+}
+
+// Receipt returns receipt of transaction or of any of its replacements. Returns ethereum.NotFound if transaction is not mined yet,
+// transaction pending for longer than StuckTxTimeout is replaced with a higher gas price.
+func (m *TransactionManager) Receipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	m.lock.Lock()
+	tx, ok := m.pending[hash]
+	var hashes []common.Hash
+	if ok {
+		hashes = append(hashes, tx.hashes...)
+	}
+	m.lock.Unlock()
+	if !ok {
+		return m.client.TransactionReceipt(ctx, hash)
+	}
+	for _, h := range hashes {
+		receipt, err := m.client.TransactionReceipt(ctx, h)
+		if err == nil {
+			m.forget(hash, true)
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+	}
+	m.replace(ctx, tx)
+	return nil, ethereum.NotFound
+}
+
+// WaitForReceipt polls Receipt until transaction is mined or ctx is done. Returns error if transaction failed,
+// transaction is not tracked anymore once ctx is done.
+func (m *TransactionManager) WaitForReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	for {
+		receipt, err := m.Receipt(ctx, hash)
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return receipt, fmt.Errorf("transaction failed on chain. Receipt status %v", receipt.Status)
+			}
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			log.Error().Err(err).Msgf("error getting tx receipt %s", hash.String())
+		}
+		select {
+		case <-ctx.Done():
+			m.Forget(hash)
+			return nil, fmt.Errorf("tx %s did not appear: %w", hash.String(), ctx.Err())
+		case <-time.After(TxPollInterval):
+		}
+	}
+}
+
+// Forget stops tracking transaction sent with hash, it is not replaced anymore. Callers that stop polling
+// for receipt of transaction before it is mined should forget it.
+func (m *TransactionManager) Forget(hash common.Hash) {
+	m.forget(hash, false)
+}
+
+// forget stops tracking transaction. Nonce is resynced if transaction may never be mined and leave a nonce gap,
+// or if it was the last pending transaction.
+func (m *TransactionManager) forget(hash common.Hash, mined bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.pending[hash]; !ok {
+		return
+	}
+	delete(m.pending, hash)
+	if !mined || len(m.pending) == 0 {
+		m.nonceStale = true
+	}
+}
+
+// fees returns priority fee and gas price or fee cap of the next transaction. Priority fee is nil for legacy transaction
+func (m *TransactionManager) fees(ctx context.Context, gasPrice *big.Int) (*big.Int, *big.Int, error) {
+	dynamicFees := false
+	if m.dynamicFabric != nil {
+		var err error
+		dynamicFees, err = m.client.UseDynamicFees(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if !dynamicFees {
+		if gasPrice != nil {
+			return nil, gasPrice, nil
+		}
+		gp, err := m.client.GasPrice()
+		return nil, gp, err
+	}
+	gasTipCap, gasFeeCap, err := m.client.GasFees(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if gasPrice != nil {
+		gasFeeCap = gasPrice
+		if gasTipCap.Cmp(gasFeeCap) == 1 {
+			gasTipCap = gasFeeCap
+		}
+	}
+	return gasTipCap, gasFeeCap, nil
+}
+
+func (m *TransactionManager) newTx(nonce uint64, to *common.Address, gasLimit uint64, gasTipCap *big.Int, gasPrice *big.Int, data []byte) CommonTransaction {
+	if gasTipCap != nil {
+		return m.dynamicFabric(nonce, to, big.NewInt(0), gasLimit, gasTipCap, gasPrice, data)
+	}
+	return m.fabric(nonce, to, big.NewInt(0), gasLimit, gasPrice, data)
+}
+
+// replace resends transaction pending for longer than StuckTxTimeout with the same nonce and bumped fees. Transaction
+// is not replaced anymore once its gas price or fee cap reaches max gas price.
+func (m *TransactionManager) replace(ctx context.Context, tx *pendingTx) {
+	m.lock.Lock()
+	if tx.replacing || time.Since(tx.sentAt) <= StuckTxTimeout {
+		m.lock.Unlock()
+		return
+	}
+	if m.maxGasPrice != nil && tx.gasPrice.Cmp(m.maxGasPrice) != -1 {
+		log.Warn().Str("hash", tx.hashes[len(tx.hashes)-1].String()).Uint64("nonce", tx.nonce).Str("gasPrice", tx.gasPrice.String()).Msg("Stuck transaction reached max gas price and is not replaced")
+		// Warn again after another StuckTxTimeout instead of on every receipt poll
+		tx.sentAt = time.Now()
+		m.lock.Unlock()
+		return
+	}
+	tx.replacing = true
+	gasPrice := bumpFee(tx.gasPrice)
+	if m.maxGasPrice != nil && gasPrice.Cmp(m.maxGasPrice) == 1 {
+		gasPrice = new(big.Int).Set(m.maxGasPrice)
+	}
+	var gasTipCap *big.Int
+	if tx.gasTipCap != nil {
+		gasTipCap = bumpFee(tx.gasTipCap)
+		if gasTipCap.Cmp(gasPrice) == 1 {
+			gasTipCap = new(big.Int).Set(gasPrice)
+		}
+	}
+	replacement := m.newTx(tx.nonce, tx.to, tx.gasLimit, gasTipCap, gasPrice, tx.data)
+	m.lock.Unlock()
+
+	hash, err := m.client.SignAndSendTransaction(ctx, replacement)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	tx.replacing = false
+	if err != nil {
+		// Nonce too low means that one of the sent transactions was mined in the meantime
+		log.Error().Err(err).Uint64("nonce", tx.nonce).Msg("Failed to replace stuck transaction")
+		return
+	}
+	log.Warn().Str("hash", hash.String()).Uint64("nonce", tx.nonce).Str("gasPrice", gasPrice.String()).Msg("Replaced stuck transaction")
+	tx.gasPrice = gasPrice
+	tx.gasTipCap = gasTipCap
+	tx.hashes = append(tx.hashes, hash)
+	tx.sentAt = time.Now()
+}
+
+func (m *TransactionManager) nextNonce(ctx context.Context) (uint64, error) {
+	m.lock.Lock()
+	if m.nonceStale {
+		m.nonce = nil
+		m.nonceStale = false
+	}
+	m.lock.Unlock()
+	if m.nonce == nil {
+		nonce, err := m.client.PendingNonceAt(ctx, m.client.From())
+		if err != nil {
+			return 0, err
+		}
+		m.nonce = new(big.Int).SetUint64(nonce)
+	}
+	return m.nonce.Uint64(), nil
+}
+
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+ReplacementGasPriceBumpPercent))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func isNonceTooLowError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
@@ -0,0 +1,346 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evmclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type mockTx struct {
+	nonce     uint64
+	gasPrice  *big.Int
+	gasTipCap *big.Int
+}
+
+func (tx *mockTx) Hash() common.Hash {
+	if tx.gasTipCap != nil {
+		return crypto.Keccak256Hash(new(big.Int).SetUint64(tx.nonce).Bytes(), tx.gasPrice.Bytes(), tx.gasTipCap.Bytes())
+	}
+	return crypto.Keccak256Hash(new(big.Int).SetUint64(tx.nonce).Bytes(), tx.gasPrice.Bytes())
+}
+
+func (tx *mockTx) RawWithSignature(key *ecdsa.PrivateKey, chainID *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func mockFabric(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) CommonTransaction {
+	return &mockTx{nonce: nonce, gasPrice: gasPrice}
+}
+
+func mockDynamicFeeFabric(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasTipCap *big.Int, gasFeeCap *big.Int, data []byte) CommonTransaction {
+	return &mockTx{nonce: nonce, gasPrice: gasFeeCap, gasTipCap: gasTipCap}
+}
+
+type mockTxClient struct {
+	dynamicFees  bool
+	pendingNonce uint64
+	sendErrs     []error
+	sent         []*mockTx
+	receipts     map[common.Hash]*types.Receipt
+	sending      chan struct{} // if set, sending signals on it that it started and waits for a signal to finish
+}
+
+func (c *mockTxClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return c.pendingNonce, nil
+}
+
+func (c *mockTxClient) SignAndSendTransaction(ctx context.Context, tx CommonTransaction) (common.Hash, error) {
+	if c.sending != nil {
+		c.sending <- struct{}{}
+		<-c.sending
+	}
+	if len(c.sendErrs) > 0 {
+		err := c.sendErrs[0]
+		c.sendErrs = c.sendErrs[1:]
+		if err != nil {
+			return common.Hash{}, err
+		}
+	}
+	c.sent = append(c.sent, tx.(*mockTx))
+	return tx.Hash(), nil
+}
+
+func (c *mockTxClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	r, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return r, nil
+}
+
+func (c *mockTxClient) GasPrice() (*big.Int, error) {
+	return big.NewInt(100), nil
+}
+
+func (c *mockTxClient) UseDynamicFees(ctx context.Context) (bool, error) {
+	return c.dynamicFees, nil
+}
+
+func (c *mockTxClient) GasFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	return big.NewInt(10), big.NewInt(210), nil
+}
+
+func (c *mockTxClient) From() common.Address {
+	return common.Address{}
+}
+
+func TestSendAssignsConsecutiveNonces(t *testing.T) {
+	client := &mockTxClient{pendingNonce: 7}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, tx := range client.sent {
+		if tx.nonce != uint64(7+i) {
+			t.Fatalf("expected nonce %d, got %d", 7+i, tx.nonce)
+		}
+	}
+}
+
+func TestSendKeepsNonceAndReleasesLockOnError(t *testing.T) {
+	client := &mockTxClient{sendErrs: []error{errors.New("insufficient funds")}}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+
+	_, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err == nil {
+		t.Fatal("expected send error")
+	}
+	_, err = m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[0].nonce != 0 {
+		t.Fatalf("expected nonce 0 to be reused, got %d", client.sent[0].nonce)
+	}
+}
+
+func TestSendResyncsNonceWhenNonceTooLow(t *testing.T) {
+	client := &mockTxClient{pendingNonce: 1}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+	_, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Transactions were sent from the same account by someone else
+	client.pendingNonce = 5
+	client.sendErrs = []error{errors.New("nonce too low")}
+	_, err = m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[1].nonce != 5 {
+		t.Fatalf("expected resynced nonce 5, got %d", client.sent[1].nonce)
+	}
+}
+
+func TestSendResyncsNonceAfterForgetOrWhenNoTransactionsArePending(t *testing.T) {
+	client := &mockTxClient{receipts: make(map[common.Hash]*types.Receipt)}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+	send := func(expectedNonce uint64) common.Hash {
+		t.Helper()
+		hash, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonce := client.sent[len(client.sent)-1].nonce; nonce != expectedNonce {
+			t.Fatalf("expected nonce %d, got %d", expectedNonce, nonce)
+		}
+		return hash
+	}
+	mine := func(hash common.Hash) {
+		t.Helper()
+		client.receipts[hash] = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+		if _, err := m.Receipt(context.Background(), hash); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := send(0)
+	second := send(1)
+	// Nonce is kept while other transactions are pending
+	mine(first)
+	client.pendingNonce = 10
+	third := send(2)
+
+	// Forgotten transaction may never be mined, its nonce is reused
+	m.Forget(second)
+	client.pendingNonce = 1
+	fourth := send(1)
+
+	mine(third)
+	mine(fourth)
+	client.pendingNonce = 5
+	send(5)
+}
+
+func TestReceiptReplacesStuckTransaction(t *testing.T) {
+	client := &mockTxClient{receipts: make(map[common.Hash]*types.Receipt)}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+	hash, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.pending[hash].sentAt = time.Now().Add(-StuckTxTimeout * 2)
+
+	_, err = m.Receipt(context.Background(), hash)
+	if !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if len(client.sent) != 2 {
+		t.Fatalf("expected replacement transaction, got %d transactions", len(client.sent))
+	}
+	replacement := client.sent[1]
+	if replacement.nonce != 0 || replacement.gasPrice.Int64() != 120 {
+		t.Fatalf("expected replacement with nonce 0 and gas price 120, got %d and %s", replacement.nonce, replacement.gasPrice)
+	}
+
+	// Receipt of replacement is returned for the original hash
+	client.receipts[replacement.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+	receipt, err := m.Receipt(context.Background(), hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatal("expected successful receipt")
+	}
+}
+
+func TestReceiptDoesNotWaitForSend(t *testing.T) {
+	client := &mockTxClient{receipts: make(map[common.Hash]*types.Receipt)}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+	hash, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.receipts[hash] = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+
+	client.sending = make(chan struct{})
+	sent := make(chan error)
+	go func() {
+		_, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+		sent <- err
+	}()
+	<-client.sending
+	_, err = m.Receipt(context.Background(), hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.sending <- struct{}{}
+	if err := <-sent; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForReceiptForgetsTransactionOnCancel(t *testing.T) {
+	client := &mockTxClient{receipts: make(map[common.Hash]*types.Receipt)}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+	hash, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = m.WaitForReceipt(ctx, hash)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled error, got %v", err)
+	}
+	if _, ok := m.pending[hash]; ok {
+		t.Fatal("expected transaction to be forgotten")
+	}
+}
+
+func TestReplacementFeesDoNotExceedMaxGasPrice(t *testing.T) {
+	client := &mockTxClient{dynamicFees: true, receipts: make(map[common.Hash]*types.Receipt)}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, big.NewInt(230))
+	hash, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		m.pending[hash].sentAt = time.Now().Add(-StuckTxTimeout * 2)
+		_, err = m.Receipt(context.Background(), hash)
+		if !errors.Is(err, ethereum.NotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	}
+	// Fee cap 210 is bumped to max gas price once, transaction at max gas price is not replaced anymore
+	if len(client.sent) != 2 {
+		t.Fatalf("expected 1 replacement transaction, got %d transactions", len(client.sent))
+	}
+	replacement := client.sent[1]
+	if replacement.gasPrice.Int64() != 230 || replacement.gasTipCap.Int64() != 12 {
+		t.Fatalf("expected replacement with fee cap 230 and tip 12, got %s and %s", replacement.gasPrice, replacement.gasTipCap)
+	}
+}
+
+func TestSendWithFees(t *testing.T) {
+	client := &mockTxClient{dynamicFees: true}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+
+	fees, err := m.SuggestFees(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.GasTipCap.Int64() != 10 || fees.GasPrice.Int64() != 210 {
+		t.Fatalf("expected tip 10 and fee cap 210, got %s and %s", fees.GasTipCap, fees.GasPrice)
+	}
+	_, err = m.SendWithFees(context.Background(), &common.Address{}, nil, 21000, &Fees{GasTipCap: big.NewInt(12), GasPrice: big.NewInt(252)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[0].gasTipCap.Int64() != 12 || client.sent[0].gasPrice.Int64() != 252 {
+		t.Fatalf("expected tip 12 and fee cap 252, got %s and %s", client.sent[0].gasTipCap, client.sent[0].gasPrice)
+	}
+}
+
+func TestSendUsesDynamicFees(t *testing.T) {
+	client := &mockTxClient{dynamicFees: true}
+	m := NewTransactionManager(client, mockFabric, mockDynamicFeeFabric, nil)
+
+	_, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Explicit gas price is used as fee cap
+	_, err = m.SendWithGasPrice(context.Background(), &common.Address{}, nil, 21000, big.NewInt(300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[0].gasTipCap.Int64() != 10 || client.sent[0].gasPrice.Int64() != 210 {
+		t.Fatalf("expected tip 10 and fee cap 210, got %s and %s", client.sent[0].gasTipCap, client.sent[0].gasPrice)
+	}
+	if client.sent[1].gasTipCap.Int64() != 10 || client.sent[1].gasPrice.Int64() != 300 {
+		t.Fatalf("expected tip 10 and fee cap 300, got %s and %s", client.sent[1].gasTipCap, client.sent[1].gasPrice)
+	}
+}
+
+func TestSendUsesLegacyTransactionsWithoutDynamicFeeFabric(t *testing.T) {
+	client := &mockTxClient{dynamicFees: true}
+	m := NewTransactionManager(client, mockFabric, nil, nil)
+
+	_, err := m.Send(context.Background(), &common.Address{}, nil, 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[0].gasTipCap != nil || client.sent[0].gasPrice.Int64() != 100 {
+		t.Fatalf("expected legacy transaction with gas price 100, got %+v", client.sent[0])
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/rlp"
 )
 
 type TX struct {
@@ -31,7 +30,8 @@ func (a *TX) RawWithSignature(key *ecdsa.PrivateKey, chainID *big.Int) ([]byte,
 		return nil, err
 	}
 	a.tx = tx
-	rawTX, err := rlp.EncodeToBytes(tx)
+	// Binary encoding is plain RLP for legacy transactions and typed envelope for dynamic fee transactions
+	rawTX, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +49,19 @@ func NewTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLimit
 	return &TX{tx: tx}
 }
 
+// NewDynamicFeeTransaction creates EIP-1559 transaction paying at most gasFeeCap per gas including gasTipCap priority fee
+func NewDynamicFeeTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasTipCap *big.Int, gasFeeCap *big.Int, data []byte) evmclient.CommonTransaction {
+	return &TX{tx: types.NewTx(&types.DynamicFeeTx{
+		Nonce:     nonce,
+		To:        to,
+		Value:     amount,
+		Gas:       gasLimit,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Data:      data,
+	})}
+}
+
 func (a *TX) Hash() common.Hash {
 	return a.tx.Hash()
 }
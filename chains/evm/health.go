@@ -0,0 +1,67 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/StirNetwork/chainbridge-core/chains/evm/calls"
+	"github.com/StirNetwork/chainbridge-core/health"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ChainClient is used by EVMChain to monitor relayer account and report chain status
+type ChainClient interface {
+	BalanceClient
+	LatestBlockContext(ctx context.Context) (*big.Int, error)
+	CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error)
+	KeypairLoaded() bool
+}
+
+// Status reports RPC availability, listener progress and whether relayer account is registered on the bridge
+func (c *EVMChain) Status(ctx context.Context) health.ChainStatus {
+	status := health.ChainStatus{ChainID: c.chainID}
+	status.LastProcessedBlock, status.LastProcessedAt = c.listener.LastProcessed()
+	if c.client == nil {
+		status.Errors = append(status.Errors, "chain client is not set")
+		return status
+	}
+
+	head, err := c.client.LatestBlockContext(ctx)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("unable to get latest block: %s", err))
+	} else {
+		status.RPCReachable = true
+		if status.LastProcessedBlock != nil {
+			status.BlocksBehindHead = new(big.Int).Sub(head, status.LastProcessedBlock)
+		}
+	}
+
+	status.KeystoreLoaded = c.client.KeypairLoaded()
+	if !status.KeystoreLoaded {
+		return status
+	}
+	status.IsRelayer, err = c.isRelayer(ctx)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("unable to check relayer registration: %s", err))
+	}
+	return status
+}
+
+func (c *EVMChain) isRelayer(ctx context.Context) (bool, error) {
+	input, err := calls.PrepareIsRelayerInput(c.client.RelayerAddress())
+	if err != nil {
+		return false, err
+	}
+	bridge := common.HexToAddress(c.config.Bridge)
+	msg := ethereum.CallMsg{From: common.Address{}, To: &bridge, Data: input}
+	out, err := c.client.CallContract(ctx, calls.ToCallArg(msg), nil)
+	if err != nil {
+		return false, err
+	}
+	return calls.ParseIsRelayerOutput(out)
+}
@@ -143,9 +143,108 @@ func Erc20EventHandler(sourceID, destId uint8, nonce uint64, handlerContractAddr
 		DepositNonce: nonce,
 		ResourceId:   out0.ResourceID,
 		Type:         relayer.FungibleTransfer,
-		Payload: []interface{}{
-			out0.Amount.Bytes(),
-			out0.DestinationRecipientAddress,
+		Depositor:    out0.Depositer.Bytes(),
+		Payload: &relayer.FungibleTransferPayload{
+			Amount:    out0.Amount,
+			Recipient: out0.DestinationRecipientAddress,
+		},
+	}, nil
+}
+
+func Erc721EventHandler(sourceID, destId uint8, nonce uint64, handlerContractAddress common.Address, client ChainClient) (*relayer.Message, error) {
+	definition := "[{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"depositNonce\",\"type\":\"uint64\"},{\"internalType\":\"uint8\",\"name\":\"destId\",\"type\":\"uint8\"}],\"name\":\"getDepositRecord\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"_tokenAddress\",\"type\":\"address\"},{\"internalType\":\"uint8\",\"name\":\"_lenDestinationRecipientAddress\",\"type\":\"uint8\"},{\"internalType\":\"uint8\",\"name\":\"_destinationChainID\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"_resourceID\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"_destinationRecipientAddress\",\"type\":\"bytes\"},{\"internalType\":\"address\",\"name\":\"_depositer\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_tokenID\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"_metaData\",\"type\":\"bytes\"}],\"internalType\":\"structERC721Handler.DepositRecord\",\"name\":\"\",\"type\":\"tuple\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]"
+	type ERC721HandlerDepositRecord struct {
+		TokenAddress                   common.Address
+		LenDestinationRecipientAddress uint8
+		DestinationChainID             uint8
+		ResourceID                     [32]byte
+		DestinationRecipientAddress    []byte
+		Depositer                      common.Address
+		TokenID                        *big.Int
+		MetaData                       []byte
+	}
+	a, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := a.Pack("getDepositRecord", nonce, destId)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := ethereum.CallMsg{From: common.Address{}, To: &handlerContractAddress, Data: input}
+	out, err := client.CallContract(context.TODO(), toCallArg(msg), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := a.Unpack("getDepositRecord", out)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, errors.New("no handler associated with such resourceID")
+	}
+
+	out0 := *abi.ConvertType(res[0], new(ERC721HandlerDepositRecord)).(*ERC721HandlerDepositRecord)
+	return &relayer.Message{
+		Source:       sourceID,
+		Destination:  destId,
+		DepositNonce: nonce,
+		ResourceId:   out0.ResourceID,
+		Type:         relayer.NonFungibleTransfer,
+		Depositor:    out0.Depositer.Bytes(),
+		Payload: &relayer.NonFungibleTransferPayload{
+			TokenID:   out0.TokenID,
+			Recipient: out0.DestinationRecipientAddress,
+			Metadata:  out0.MetaData,
+		},
+	}, nil
+}
+
+func GenericEventHandler(sourceID, destId uint8, nonce uint64, handlerContractAddress common.Address, client ChainClient) (*relayer.Message, error) {
+	definition := "[{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"depositNonce\",\"type\":\"uint64\"},{\"internalType\":\"uint8\",\"name\":\"destId\",\"type\":\"uint8\"}],\"name\":\"getDepositRecord\",\"outputs\":[{\"components\":[{\"internalType\":\"uint8\",\"name\":\"_destinationChainID\",\"type\":\"uint8\"},{\"internalType\":\"address\",\"name\":\"_depositer\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"_resourceID\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"_metaData\",\"type\":\"bytes\"}],\"internalType\":\"structGenericHandler.DepositRecord\",\"name\":\"\",\"type\":\"tuple\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]"
+	type GenericHandlerDepositRecord struct {
+		DestinationChainID uint8
+		Depositer          common.Address
+		ResourceID         [32]byte
+		MetaData           []byte
+	}
+	a, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := a.Pack("getDepositRecord", nonce, destId)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := ethereum.CallMsg{From: common.Address{}, To: &handlerContractAddress, Data: input}
+	out, err := client.CallContract(context.TODO(), toCallArg(msg), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := a.Unpack("getDepositRecord", out)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, errors.New("no handler associated with such resourceID")
+	}
+
+	out0 := *abi.ConvertType(res[0], new(GenericHandlerDepositRecord)).(*GenericHandlerDepositRecord)
+	return &relayer.Message{
+		Source:       sourceID,
+		Destination:  destId,
+		DepositNonce: nonce,
+		ResourceId:   out0.ResourceID,
+		Type:         relayer.GenericTransfer,
+		Depositor:    out0.Depositer.Bytes(),
+		Payload: &relayer.GenericTransferPayload{
+			Metadata: out0.MetaData,
 		},
 	}, nil
 }
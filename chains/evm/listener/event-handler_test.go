@@ -0,0 +1,221 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package listener
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type mockContractCaller struct {
+	mockChainClient
+	out   []byte
+	err   error
+	calls []map[string]interface{}
+}
+
+func (c *mockContractCaller) CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error) {
+	c.calls = append(c.calls, callArgs)
+	return c.out, c.err
+}
+
+// packDepositRecord ABI encodes deposit record returned by handler getDepositRecord
+func packDepositRecord(t *testing.T, components []abi.ArgumentMarshaling, record interface{}) []byte {
+	recordType, err := abi.NewType("tuple", "", components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := abi.Arguments{{Type: recordType}}.Pack(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestErc20EventHandler(t *testing.T) {
+	record := struct {
+		TokenAddress                   common.Address
+		LenDestinationRecipientAddress uint8
+		DestinationChainID             uint8
+		ResourceID                     [32]byte
+		DestinationRecipientAddress    []byte
+		Depositer                      common.Address
+		Amount                         *big.Int
+	}{
+		TokenAddress:                   common.HexToAddress("0x1"),
+		LenDestinationRecipientAddress: 20,
+		DestinationChainID:             2,
+		ResourceID:                     [32]byte{1},
+		DestinationRecipientAddress:    common.HexToAddress("0x2").Bytes(),
+		Depositer:                      common.HexToAddress("0x3"),
+		Amount:                         big.NewInt(42),
+	}
+	client := &mockContractCaller{out: packDepositRecord(t, []abi.ArgumentMarshaling{
+		{Name: "_tokenAddress", Type: "address"},
+		{Name: "_lenDestinationRecipientAddress", Type: "uint8"},
+		{Name: "_destinationChainID", Type: "uint8"},
+		{Name: "_resourceID", Type: "bytes32"},
+		{Name: "_destinationRecipientAddress", Type: "bytes"},
+		{Name: "_depositer", Type: "address"},
+		{Name: "_amount", Type: "uint256"},
+	}, record)}
+
+	m, err := Erc20EventHandler(1, 2, 3, common.HexToAddress("0x4"), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Source != 1 || m.Destination != 2 || m.DepositNonce != 3 || m.ResourceId != record.ResourceID || m.Type != relayer.FungibleTransfer {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if !bytes.Equal(m.Depositor, record.Depositer.Bytes()) {
+		t.Fatalf("unexpected depositor %x", m.Depositor)
+	}
+	payload, err := m.FungibleTransferPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.Amount.Cmp(record.Amount) != 0 || !bytes.Equal(payload.Recipient, record.DestinationRecipientAddress) {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestErc721EventHandler(t *testing.T) {
+	record := struct {
+		TokenAddress                   common.Address
+		LenDestinationRecipientAddress uint8
+		DestinationChainID             uint8
+		ResourceID                     [32]byte
+		DestinationRecipientAddress    []byte
+		Depositer                      common.Address
+		TokenID                        *big.Int
+		MetaData                       []byte
+	}{
+		TokenAddress:                   common.HexToAddress("0x1"),
+		LenDestinationRecipientAddress: 20,
+		DestinationChainID:             2,
+		ResourceID:                     [32]byte{1},
+		DestinationRecipientAddress:    common.HexToAddress("0x2").Bytes(),
+		Depositer:                      common.HexToAddress("0x3"),
+		TokenID:                        big.NewInt(42),
+		MetaData:                       []byte("metadata"),
+	}
+	client := &mockContractCaller{out: packDepositRecord(t, []abi.ArgumentMarshaling{
+		{Name: "_tokenAddress", Type: "address"},
+		{Name: "_lenDestinationRecipientAddress", Type: "uint8"},
+		{Name: "_destinationChainID", Type: "uint8"},
+		{Name: "_resourceID", Type: "bytes32"},
+		{Name: "_destinationRecipientAddress", Type: "bytes"},
+		{Name: "_depositer", Type: "address"},
+		{Name: "_tokenID", Type: "uint256"},
+		{Name: "_metaData", Type: "bytes"},
+	}, record)}
+	handlerAddress := common.HexToAddress("0x4")
+
+	m, err := Erc721EventHandler(1, 2, 3, handlerAddress, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.calls) != 1 || *client.calls[0]["to"].(*common.Address) != handlerAddress {
+		t.Fatalf("expected deposit record to be requested from handler %s", handlerAddress.Hex())
+	}
+	if m.Source != 1 || m.Destination != 2 || m.DepositNonce != 3 || m.ResourceId != record.ResourceID || m.Type != relayer.NonFungibleTransfer ||
+		!bytes.Equal(m.Depositor, record.Depositer.Bytes()) {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	payload, err := m.NonFungibleTransferPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.TokenID.Cmp(record.TokenID) != 0 ||
+		!bytes.Equal(payload.Recipient, record.DestinationRecipientAddress) ||
+		!bytes.Equal(payload.Metadata, record.MetaData) {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+var genericDepositRecordComponents = []abi.ArgumentMarshaling{
+	{Name: "_destinationChainID", Type: "uint8"},
+	{Name: "_depositer", Type: "address"},
+	{Name: "_resourceID", Type: "bytes32"},
+	{Name: "_metaData", Type: "bytes"},
+}
+
+type genericDepositRecord struct {
+	DestinationChainID uint8
+	Depositer          common.Address
+	ResourceID         [32]byte
+	MetaData           []byte
+}
+
+func TestGenericEventHandler(t *testing.T) {
+	record := genericDepositRecord{
+		DestinationChainID: 2,
+		Depositer:          common.HexToAddress("0x3"),
+		ResourceID:         [32]byte{1},
+		MetaData:           []byte("metadata"),
+	}
+	client := &mockContractCaller{out: packDepositRecord(t, genericDepositRecordComponents, record)}
+	handlerAddress := common.HexToAddress("0x4")
+
+	m, err := GenericEventHandler(1, 2, 3, handlerAddress, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.calls) != 1 || *client.calls[0]["to"].(*common.Address) != handlerAddress {
+		t.Fatalf("expected deposit record to be requested from handler %s", handlerAddress.Hex())
+	}
+	if m.Source != 1 || m.Destination != 2 || m.DepositNonce != 3 || m.ResourceId != record.ResourceID || m.Type != relayer.GenericTransfer ||
+		!bytes.Equal(m.Depositor, record.Depositer.Bytes()) {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	payload, err := m.GenericTransferPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload.Metadata, record.MetaData) {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestGenericEventHandlerEmptyMetadata(t *testing.T) {
+	record := genericDepositRecord{ResourceID: [32]byte{1}, MetaData: []byte{}}
+	client := &mockContractCaller{out: packDepositRecord(t, genericDepositRecordComponents, record)}
+
+	m, err := GenericEventHandler(1, 2, 3, common.HexToAddress("0x4"), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := m.GenericTransferPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Metadata) != 0 {
+		t.Fatalf("expected empty metadata, got %+v", payload)
+	}
+}
+
+func TestGenericEventHandlerCallError(t *testing.T) {
+	client := &mockContractCaller{err: errors.New("connection refused")}
+
+	_, err := GenericEventHandler(1, 2, 3, common.HexToAddress("0x4"), client)
+	if err == nil {
+		t.Fatal("expected call error")
+	}
+}
+
+func TestGenericEventHandlerMalformedRecord(t *testing.T) {
+	client := &mockContractCaller{out: []byte{1, 2, 3}}
+
+	_, err := GenericEventHandler(1, 2, 3, common.HexToAddress("0x4"), client)
+	if err == nil {
+		t.Fatal("expected unpack error")
+	}
+}
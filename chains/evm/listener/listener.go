@@ -5,26 +5,42 @@ package listener
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/StirNetwork/chainbridge-core/blockstore"
+	"github.com/StirNetwork/chainbridge-core/metrics"
 	"github.com/StirNetwork/chainbridge-core/relayer"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/rs/zerolog/log"
 )
 
-var BlockRetryInterval = time.Second * 5
-var BlockDelay = big.NewInt(10) //TODO: move to config
-
 type DepositLogs struct {
 	DestinationID uint8
 	ResourceID    [32]byte
 	DepositNonce  uint64
 }
 
+// ReorgHistorySize is the number of last processed block ranges kept to find common ancestor on reorg
+var ReorgHistorySize = 128
+
+// BlockRangeGrowthInterval is the number of ranges fetched with shrunk block range before it is doubled back towards configured range
+var BlockRangeGrowthInterval = 10
+
+// ListenerName identifies checkpoints of EVM deposit listener in blockstore
+const ListenerName = "deposits"
+
+var ErrReorgTooDeep = errors.New("reorg is deeper than tracked block history")
+
 type ChainClient interface {
-	LatestBlock() (*big.Int, error)
+	LatestBlockContext(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	FetchDepositLogs(ctx context.Context, address common.Address, startBlock *big.Int, endBlock *big.Int) ([]*DepositLogs, error)
 	CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error)
 }
@@ -34,66 +50,300 @@ type EventHandler interface {
 }
 
 type EVMListener struct {
-	chainReader   ChainClient
-	eventHandler  EventHandler
-	bridgeAddress common.Address
+	chainReader        ChainClient
+	eventHandler       EventHandler
+	bridgeAddress      common.Address
+	blockRange         *big.Int
+	blockConfirmations *big.Int
+	blockRetryInterval time.Duration
+	reorgMetrics       *metrics.ReorgMetrics
+	stateMetrics       *metrics.ChainStateMetrics
+
+	lock            sync.Mutex
+	lastProcessed   *big.Int
+	lastProcessedAt time.Time
+}
+
+// NewEVMListener creates listener that fetches deposit logs in batches of at most blockRange blocks.
+// Blocks are processed once they have blockConfirmations confirmations, head is polled every blockRetryInterval.
+func NewEVMListener(chainReader ChainClient, handler EventHandler, bridgeAddress common.Address, blockRange *big.Int, blockConfirmations *big.Int, blockRetryInterval time.Duration, reorgMetrics *metrics.ReorgMetrics, stateMetrics *metrics.ChainStateMetrics) *EVMListener {
+	return &EVMListener{
+		chainReader:        chainReader,
+		eventHandler:       handler,
+		bridgeAddress:      bridgeAddress,
+		blockRange:         blockRange,
+		blockConfirmations: blockConfirmations,
+		blockRetryInterval: blockRetryInterval,
+		reorgMetrics:       reorgMetrics,
+		stateMetrics:       stateMetrics,
+	}
+}
+
+// processedRange is a block range that listener already processed and messages it sent from that range
+type processedRange struct {
+	block    *big.Int // last block of the range
+	hash     common.Hash
+	messages []*relayer.Message
 }
 
-func NewEVMListener(chainReader ChainClient, handler EventHandler, bridgeAddress common.Address) *EVMListener {
-	return &EVMListener{chainReader: chainReader, eventHandler: handler, bridgeAddress: bridgeAddress}
+// Name identifies checkpoints of listener in blockstore
+func (l *EVMListener) Name() string {
+	return ListenerName
 }
 
-func (l *EVMListener) ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message {
-	// TODO: This channel should be closed somewhere!
+// ListenToEvents fetches deposit logs from startBlock on. Last block of every processed range is checkpointed in
+// blockstore with its hash, which is checked against parent hash of the next block. On mismatch listener rolls back
+// to the common ancestor and re-scans. Failed RPC calls and event handling are retried every blockRetryInterval.
+// RPC calls in progress are cancelled once stopChn is closed. Returned channel is closed once listener stops.
+func (l *EVMListener) ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueReaderWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message {
 	ch := make(chan *relayer.Message)
+	l.setLastProcessed(nil)
 	go func() {
+		// Closing ch tells reader that listener stopped and no more messages are sent
+		defer close(ch)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-stopChn:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		// blockRange shrinks when provider rejects range as too large and grows back after BlockRangeGrowthInterval fetched ranges
+		blockRange := new(big.Int).Set(l.blockRange)
+		fetchedSinceShrink := 0
+		history := l.loadHistory(chainID, kvrw)
 		for {
 			select {
 			case <-stopChn:
 				return
 			default:
-				head, err := l.chainReader.LatestBlock()
+				head, err := l.chainReader.LatestBlockContext(ctx)
 				if err != nil {
 					log.Error().Err(err).Msg("Unable to get latest block")
-					time.Sleep(BlockRetryInterval)
+					l.wait(ctx)
 					continue
 				}
-				// Sleep if the difference is less than BlockDelay; (latest - current) < BlockDelay
-				if big.NewInt(0).Sub(head, startBlock).Cmp(BlockDelay) == -1 {
-					time.Sleep(BlockRetryInterval)
+				// Sleep if the difference is less than blockConfirmations; (latest - current) < blockConfirmations
+				safeHead := new(big.Int).Sub(head, l.blockConfirmations)
+				if startBlock.Cmp(safeHead) == 1 {
+					l.wait(ctx)
+					continue
+				}
+				reorged, err := l.isParentReorged(ctx, startBlock, history)
+				if err != nil {
+					log.Error().Err(err).Uint8("chainID", chainID).Str("block", startBlock.String()).Msg("Unable to verify parent block hash")
+					l.wait(ctx)
+					continue
+				}
+				if reorged {
+					ancestor, err := l.findCommonAncestor(ctx, history)
+					if err != nil {
+						if errors.Is(err, ErrReorgTooDeep) {
+							errChn <- fmt.Errorf("chain %d reorg at block %s: %w", chainID, startBlock.String(), err)
+							return
+						}
+						log.Error().Err(err).Uint8("chainID", chainID).Msg("Unable to find common ancestor of reorged blocks")
+						l.wait(ctx)
+						continue
+					}
+					if ancestor == len(history)-1 {
+						// Last processed block is canonical again, check parent on the next iteration
+						l.wait(ctx)
+						continue
+					}
+					l.reportOrphaned(chainID, history[ancestor+1:])
+					history = history[:ancestor+1]
+					startBlock.Add(history[ancestor].block, big.NewInt(1))
+					_, err = blockstore.RollbackCheckpoint(kvrw, chainID, l.Name(), history[ancestor].block)
+					if err != nil {
+						log.Error().Str("block", history[ancestor].block.String()).Err(err).Msg("Failed to roll back checkpoint in blockstore")
+					}
+					continue
+				}
+				endBlock := new(big.Int).Add(startBlock, blockRange)
+				endBlock.Sub(endBlock, big.NewInt(1))
+				if endBlock.Cmp(safeHead) == 1 {
+					endBlock = safeHead
+				}
+				endHeader, err := l.chainReader.HeaderByNumber(ctx, endBlock)
+				if err != nil {
+					log.Error().Err(err).Uint8("chainID", chainID).Str("block", endBlock.String()).Msg("Unable to get block header")
+					l.wait(ctx)
 					continue
 				}
-				logs, err := l.chainReader.FetchDepositLogs(context.Background(), l.bridgeAddress, startBlock, startBlock)
+				logs, err := l.chainReader.FetchDepositLogs(ctx, l.bridgeAddress, startBlock, endBlock)
 				if err != nil {
+					if isBlockRangeError(err) && blockRange.Cmp(big.NewInt(1)) == 1 {
+						blockRange.Div(blockRange, big.NewInt(2))
+						fetchedSinceShrink = 0
+						log.Warn().Err(err).Uint8("chainID", chainID).Str("range", blockRange.String()).Msg("Block range rejected, shrinking range")
+						continue
+					}
 					// Filtering logs error really can appear only on wrong configuration or temporary network problem
 					// so i do no see any reason to break execution
-					log.Error().Err(err).Str("ChainID", string(chainID)).Msgf("Unable to filter logs")
+					log.Error().Err(err).Uint8("chainID", chainID).Msgf("Unable to filter logs")
+					l.wait(ctx)
 					continue
 				}
+				if blockRange.Cmp(l.blockRange) == -1 {
+					fetchedSinceShrink++
+					if fetchedSinceShrink >= BlockRangeGrowthInterval {
+						blockRange.Mul(blockRange, big.NewInt(2))
+						if blockRange.Cmp(l.blockRange) == 1 {
+							blockRange.Set(l.blockRange)
+						}
+						fetchedSinceShrink = 0
+						log.Debug().Uint8("chainID", chainID).Str("range", blockRange.String()).Msg("Growing block range")
+					}
+				}
+				processed := &processedRange{block: new(big.Int).Set(endBlock), hash: endHeader.Hash()}
 				for _, eventLog := range logs {
 					m, err := l.eventHandler.HandleEvent(chainID, eventLog.DestinationID, eventLog.DepositNonce, eventLog.ResourceID)
-					if err != nil {
-						errChn <- err
-						log.Error().Err(err)
-						return
+					for err != nil {
+						log.Error().Err(err).Uint8("chainID", chainID).Uint64("nonce", eventLog.DepositNonce).Msg("Unable to handle deposit event")
+						l.wait(ctx)
+						if ctx.Err() != nil {
+							return
+						}
+						m, err = l.eventHandler.HandleEvent(chainID, eventLog.DestinationID, eventLog.DepositNonce, eventLog.ResourceID)
 					}
-					log.Debug().Msgf("Resolved message %+v in block %s", m, startBlock.String())
+					log.Debug().Msgf("Resolved message %+v in blocks %s-%s", m, startBlock.String(), endBlock.String())
 					ch <- m
+					processed.messages = append(processed.messages, m)
 				}
-				if startBlock.Int64()%20 == 0 {
-					// Logging process every 20 bocks to exclude spam
-					log.Debug().Str("block", startBlock.String()).Uint8("chainID", chainID).Msg("Queried block for deposit events")
+				log.Debug().Str("from", startBlock.String()).Str("to", endBlock.String()).Uint8("chainID", chainID).Msg("Queried blocks for deposit events")
+				history = append(history, processed)
+				if len(history) > ReorgHistorySize {
+					history = history[1:]
 				}
-				// TODO: We can store blocks to DB inside listener or make listener send something to channel each block to save it.
 				//Write to block store. Not a critical operation, no need to retry
-				err = blockstore.StoreBlock(kvrw, startBlock, chainID)
+				err = blockstore.StoreCheckpoint(kvrw, chainID, l.Name(), &blockstore.Checkpoint{Block: endBlock, Hash: processed.hash.Bytes(), Timestamp: time.Now()})
 				if err != nil {
-					log.Error().Str("block", startBlock.String()).Err(err).Msg("Failed to write latest block to blockstore")
+					log.Error().Str("block", endBlock.String()).Err(err).Msg("Failed to write checkpoint to blockstore")
 				}
-				// Goto next block
-				startBlock.Add(startBlock, big.NewInt(1))
+				l.stateMetrics.ProcessedBlock(chainID, endBlock, head)
+				l.setLastProcessed(endBlock)
+				// Goto next range
+				startBlock.Add(endBlock, big.NewInt(1))
 			}
 		}
 	}()
 	return ch
 }
+
+// wait sleeps for blockRetryInterval or until ctx is done
+func (l *EVMListener) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(l.blockRetryInterval):
+	}
+}
+
+// LastProcessed returns the last block of the latest processed range and when it was processed.
+// Block is nil and time is listener start until the first range is processed.
+func (l *EVMListener) LastProcessed() (*big.Int, time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.lastProcessed == nil {
+		return nil, l.lastProcessedAt
+	}
+	return new(big.Int).Set(l.lastProcessed), l.lastProcessedAt
+}
+
+func (l *EVMListener) setLastProcessed(block *big.Int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if block != nil {
+		l.lastProcessed = new(big.Int).Set(block)
+	}
+	l.lastProcessedAt = time.Now()
+}
+
+// loadHistory returns ranges processed before restart from checkpoint history, without their messages,
+// so reorgs of blocks processed before restart are rolled back too
+func (l *EVMListener) loadHistory(chainID uint8, kvr blockstore.KeyValueReader) []*processedRange {
+	history := make([]*processedRange, 0, ReorgHistorySize)
+	checkpoints, err := blockstore.CheckpointHistory(kvr, chainID, l.Name())
+	if err != nil {
+		log.Warn().Err(err).Uint8("chainID", chainID).Msg("Unable to load checkpoint history, reorgs of blocks processed before restart are not rolled back")
+		return history
+	}
+	for _, cp := range checkpoints {
+		if len(cp.Hash) == 0 {
+			continue
+		}
+		history = append(history, &processedRange{block: cp.Block, hash: common.BytesToHash(cp.Hash)})
+	}
+	if len(history) > ReorgHistorySize {
+		history = history[len(history)-ReorgHistorySize:]
+	}
+	return history
+}
+
+// isParentReorged compares parent hash of block with the hash of previous processed block.
+// Returns false if previous block is not the last block of a processed range.
+func (l *EVMListener) isParentReorged(ctx context.Context, block *big.Int, history []*processedRange) (bool, error) {
+	parent := new(big.Int).Sub(block, big.NewInt(1))
+	if len(history) == 0 || history[len(history)-1].block.Cmp(parent) != 0 {
+		return false, nil
+	}
+	header, err := l.chainReader.HeaderByNumber(ctx, block)
+	if err != nil {
+		return false, err
+	}
+	return header.ParentHash != history[len(history)-1].hash, nil
+}
+
+// findCommonAncestor returns index of the latest processed range whose last block is still canonical
+func (l *EVMListener) findCommonAncestor(ctx context.Context, history []*processedRange) (int, error) {
+	for i := len(history) - 1; i >= 0; i-- {
+		header, err := l.chainReader.HeaderByNumber(ctx, history[i].block)
+		if err != nil {
+			return 0, err
+		}
+		if header.Hash() == history[i].hash {
+			return i, nil
+		}
+	}
+	return 0, ErrReorgTooDeep
+}
+
+// reportOrphaned alerts about messages sent from rolled back ranges. Messages that are still canonical are
+// found again on re-scan and deduplicated by relayer, others were deposited in orphaned blocks. Relayer replaces
+// those by canonical deposits found on re-scan with the same nonce.
+func (l *EVMListener) reportOrphaned(chainID uint8, orphaned []*processedRange) {
+	chain := strconv.Itoa(int(chainID))
+	l.reorgMetrics.Reorgs.WithLabelValues(chain).Inc()
+	log.Warn().Uint8("chainID", chainID).Str("from", orphaned[0].block.String()).Msg("Chain reorg detected, rolling back to common ancestor")
+	for _, r := range orphaned {
+		for _, m := range r.messages {
+			l.reorgMetrics.OrphanedMessages.WithLabelValues(chain).Inc()
+			log.Warn().Uint8("chainID", chainID).Uint8("destination", m.Destination).Uint64("nonce", m.DepositNonce).Str("block", r.block.String()).Msg("Relayed message may be deposited in orphaned block")
+		}
+	}
+}
+
+// blockRangeErrors are fragments of errors returned by RPC providers when eth_getLogs range or response is too large.
+// Rate limit and timeout errors are not among them, smaller range does not help with those.
+var blockRangeErrors = []string{
+	"block range is too",
+	"block range too",
+	"exceed maximum block range",
+	"range too large",
+	"range is too large",
+	"query returned more than",
+	"log response size exceeded",
+	"response size exceeded",
+}
+
+func isBlockRangeError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range blockRangeErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,458 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StirNetwork/chainbridge-core/blockstore"
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/StirNetwork/chainbridge-core/metrics"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+type fetchRange struct {
+	from, to int64
+}
+
+type mockChainClient struct {
+	lock     sync.Mutex
+	head     *big.Int
+	maxRange int64
+	// if set, only the first rejections ranges over maxRange are rejected
+	rejections int
+	logs       map[int64][]*DepositLogs
+	requested  []fetchRange
+	// blocks starting from forkBlock belong to the fork named forkName
+	forkBlock int64
+	forkName  string
+	// if set, latest block is not returned until ctx is done
+	blockHead bool
+}
+
+func (c *mockChainClient) LatestBlockContext(ctx context.Context) (*big.Int, error) {
+	c.lock.Lock()
+	blockHead := c.blockHead
+	c.lock.Unlock()
+	if blockHead {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return new(big.Int).Set(c.head), nil
+}
+
+func (c *mockChainClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var parent common.Hash
+	var header *types.Header
+	for n := int64(0); n <= number.Int64(); n++ {
+		header = &types.Header{Number: big.NewInt(n), ParentHash: parent}
+		if c.forkName != "" && n >= c.forkBlock {
+			header.Extra = []byte(c.forkName)
+		}
+		parent = header.Hash()
+	}
+	return header, nil
+}
+
+func (c *mockChainClient) reorg(forkBlock int64, forkName string, head *big.Int, logs map[int64][]*DepositLogs) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.forkBlock = forkBlock
+	c.forkName = forkName
+	c.head = head
+	c.logs = logs
+	c.requested = nil
+}
+
+func (c *mockChainClient) FetchDepositLogs(ctx context.Context, address common.Address, startBlock *big.Int, endBlock *big.Int) ([]*DepositLogs, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requested = append(c.requested, fetchRange{startBlock.Int64(), endBlock.Int64()})
+	if c.maxRange != 0 && endBlock.Int64()-startBlock.Int64()+1 > c.maxRange {
+		if c.rejections > 0 {
+			c.rejections--
+			if c.rejections == 0 {
+				c.maxRange = 0
+			}
+		}
+		return nil, errors.New("exceed maximum block range: 5")
+	}
+	logs := make([]*DepositLogs, 0)
+	for b := startBlock.Int64(); b <= endBlock.Int64(); b++ {
+		logs = append(logs, c.logs[b]...)
+	}
+	return logs, nil
+}
+
+func (c *mockChainClient) CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (c *mockChainClient) fetched() []fetchRange {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]fetchRange{}, c.requested...)
+}
+
+type mockEventHandler struct {
+	lock     sync.Mutex
+	failures int // number of the first calls that fail
+	calls    int
+}
+
+func (h *mockEventHandler) HandleEvent(sourceID, destID uint8, nonce uint64, rID [32]byte) (*relayer.Message, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.calls++
+	if h.calls <= h.failures {
+		return nil, errors.New("handler call failed")
+	}
+	return &relayer.Message{Source: sourceID, Destination: destID, DepositNonce: nonce, ResourceId: rID}, nil
+}
+
+type mockBlockstore struct {
+	lock   sync.Mutex
+	stored int
+	values map[string][]byte
+}
+
+func (s *mockBlockstore) SetByKey(key []byte, value []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.values == nil {
+		s.values = make(map[string][]byte)
+	}
+	if strings.HasSuffix(string(key), ":checkpoint") {
+		s.stored++
+	}
+	s.values[string(key)] = value
+	return nil
+}
+
+func (s *mockBlockstore) GetByKey(key []byte) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	v, ok := s.values[string(key)]
+	if !ok {
+		return nil, leveldb.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *mockBlockstore) count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stored
+}
+
+func newTestReorgMetrics() *metrics.ReorgMetrics {
+	return &metrics.ReorgMetrics{
+		Reorgs:           prometheus.NewCounterVec(prometheus.CounterOpts{Name: "reorgs"}, []string{"chain"}),
+		OrphanedMessages: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "orphaned_messages"}, []string{"chain"}),
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Second * 5)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for listener")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestListenToEventsFetchesBlockRanges(t *testing.T) {
+	client := &mockChainClient{
+		head: big.NewInt(35),
+		logs: map[int64][]*DepositLogs{
+			3:  {{DestinationID: 2, DepositNonce: 1}},
+			14: {{DestinationID: 2, DepositNonce: 2}},
+		},
+	}
+	kv := &mockBlockstore{}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	l := NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(10), big.NewInt(10), time.Millisecond, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+	ch := l.ListenToEvents(big.NewInt(1), 1, kv, stop, make(chan error, 1))
+
+	for _, nonce := range []uint64{1, 2} {
+		m := <-ch
+		if m.DepositNonce != nonce {
+			t.Fatalf("expected deposit nonce %d, got %d", nonce, m.DepositNonce)
+		}
+	}
+	// Head 35 with block delay 10 allows processing up to block 25
+	waitFor(t, func() bool { return kv.count() == 3 })
+	expected := []fetchRange{{1, 10}, {11, 20}, {21, 25}}
+	requested := client.fetched()
+	for i, r := range expected {
+		if requested[i] != r {
+			t.Fatalf("expected range %v, got %v", r, requested[i])
+		}
+	}
+	waitFor(t, func() bool {
+		block, _ := l.LastProcessed()
+		return block != nil && block.Int64() == 25
+	})
+}
+
+func TestListenToEventsWaitsForConfirmations(t *testing.T) {
+	for _, confirmations := range []int64{0, 3} {
+		client := &mockChainClient{head: big.NewInt(10)}
+		kv := &mockBlockstore{}
+		stop := make(chan struct{})
+
+		l := NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(100), big.NewInt(confirmations), time.Millisecond, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+		l.ListenToEvents(big.NewInt(1), 1, kv, stop, make(chan error, 1))
+
+		waitFor(t, func() bool { return kv.count() == 1 })
+		if requested := client.fetched(); requested[0] != (fetchRange{1, 10 - confirmations}) {
+			t.Fatalf("%d confirmations: expected range up to block %d, got %v", confirmations, 10-confirmations, requested[0])
+		}
+		// Newer blocks are not processed until they are confirmed
+		time.Sleep(time.Millisecond * 10)
+		if kv.count() != 1 {
+			t.Fatalf("%d confirmations: expected unconfirmed blocks to wait, got %v", confirmations, client.fetched())
+		}
+		client.lock.Lock()
+		client.head = big.NewInt(12)
+		client.lock.Unlock()
+		waitFor(t, func() bool { return kv.count() == 2 })
+		if requested := client.fetched(); requested[1] != (fetchRange{11 - confirmations, 12 - confirmations}) {
+			t.Fatalf("%d confirmations: expected range up to block %d, got %v", confirmations, 12-confirmations, requested[1])
+		}
+		close(stop)
+	}
+}
+
+func TestListenToEventsRetriesFailedEventHandling(t *testing.T) {
+	client := &mockChainClient{head: big.NewInt(10), logs: map[int64][]*DepositLogs{5: {{DestinationID: 2, DepositNonce: 1}}}}
+	handler := &mockEventHandler{failures: 2}
+	stop := make(chan struct{})
+	defer close(stop)
+	errChn := make(chan error, 1)
+
+	l := NewEVMListener(client, handler, common.Address{}, big.NewInt(100), big.NewInt(0), time.Millisecond, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+	ch := l.ListenToEvents(big.NewInt(1), 1, &mockBlockstore{}, stop, errChn)
+
+	select {
+	case m := <-ch:
+		if m.DepositNonce != 1 {
+			t.Fatalf("unexpected message %+v", m)
+		}
+	case err := <-errChn:
+		t.Fatalf("expected failed event handling to be retried, got error %v", err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func TestListenToEventsStopsWithoutWaiting(t *testing.T) {
+	for name, client := range map[string]*mockChainClient{
+		"waiting for confirmations": {head: big.NewInt(0)},
+		"waiting for RPC call":      {head: big.NewInt(10), blockHead: true},
+	} {
+		stop := make(chan struct{})
+		l := NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(100), big.NewInt(0), time.Hour, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+		ch := l.ListenToEvents(big.NewInt(1), 1, &mockBlockstore{}, stop, make(chan error, 1))
+
+		time.Sleep(time.Millisecond * 10)
+		close(stop)
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Fatalf("%s: unexpected message", name)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("%s: listener did not stop", name)
+		}
+	}
+}
+
+func TestListenToEventsShrinksRejectedRange(t *testing.T) {
+	client := &mockChainClient{
+		head:     big.NewInt(30),
+		maxRange: 5,
+	}
+	kv := &mockBlockstore{}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	l := NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(20), big.NewInt(10), time.Millisecond, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+	l.ListenToEvents(big.NewInt(1), 1, kv, stop, make(chan error, 1))
+
+	waitFor(t, func() bool { return kv.count() >= 2 })
+	requested := client.fetched()
+	expected := []fetchRange{{1, 20}, {1, 10}, {1, 5}, {6, 10}}
+	for i, r := range expected {
+		if requested[i] != r {
+			t.Fatalf("expected range %v, got %v", r, requested[i])
+		}
+	}
+}
+
+func TestListenToEventsGrowsShrunkRange(t *testing.T) {
+	defer func(interval int) { BlockRangeGrowthInterval = interval }(BlockRangeGrowthInterval)
+	BlockRangeGrowthInterval = 2
+	client := &mockChainClient{
+		head:       big.NewInt(70),
+		maxRange:   10,
+		rejections: 1,
+	}
+	kv := &mockBlockstore{}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	l := NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(20), big.NewInt(10), time.Millisecond, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+	l.ListenToEvents(big.NewInt(1), 1, kv, stop, make(chan error, 1))
+
+	waitFor(t, func() bool { return kv.count() == 4 })
+	// Range grows back to 20 after two ranges are fetched with shrunk range
+	expected := []fetchRange{{1, 20}, {1, 10}, {11, 20}, {21, 40}, {41, 60}}
+	requested := client.fetched()
+	if len(requested) != len(expected) {
+		t.Fatalf("expected ranges %v, got %v", expected, requested)
+	}
+	for i, r := range expected {
+		if requested[i] != r {
+			t.Fatalf("expected range %v, got %v", r, requested[i])
+		}
+	}
+}
+
+func TestIsBlockRangeError(t *testing.T) {
+	for msg, expected := range map[string]bool{
+		"query returned more than 10000 results":               true,
+		"Log response size exceeded. You can make eth_getLogs": true,
+		"exceed maximum block range: 5000":                     true,
+		"block range is too wide":                              true,
+		"429 Too Many Requests":                                false,
+		"daily request limit exceeded":                         false,
+		"context deadline exceeded (Client.Timeout exceeded)":  false,
+		"more than one block with the same number":             false,
+	} {
+		if isBlockRangeError(errors.New(msg)) != expected {
+			t.Fatalf("expected %q to be block range error: %t", msg, expected)
+		}
+	}
+}
+
+func TestListenToEventsRollsBackReorgedBlocks(t *testing.T) {
+	client := &mockChainClient{
+		head: big.NewInt(20),
+		logs: map[int64][]*DepositLogs{
+			7:  {{DestinationID: 2, DepositNonce: 1}},
+			17: {{DestinationID: 2, DepositNonce: 2}},
+		},
+	}
+	kv := &mockBlockstore{}
+	stop := make(chan struct{})
+	defer close(stop)
+	reorgMetrics := newTestReorgMetrics()
+
+	l := NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(5), big.NewInt(0), time.Millisecond, reorgMetrics, metrics.NewChainStateMetrics())
+	ch := l.ListenToEvents(big.NewInt(1), 1, kv, stop, make(chan error, 1))
+	for _, nonce := range []uint64{1, 2} {
+		m := <-ch
+		if m.DepositNonce != nonce {
+			t.Fatalf("expected deposit nonce %d, got %d", nonce, m.DepositNonce)
+		}
+	}
+	waitFor(t, func() bool { return kv.count() == 4 })
+
+	// Blocks from 13 are replaced, deposit from block 17 is gone and a new one is in block 22
+	client.reorg(13, "b", big.NewInt(25), map[int64][]*DepositLogs{
+		7:  {{DestinationID: 2, DepositNonce: 1}},
+		22: {{DestinationID: 2, DepositNonce: 3}},
+	})
+	m := <-ch
+	if m.DepositNonce != 3 {
+		t.Fatalf("expected deposit nonce 3, got %d", m.DepositNonce)
+	}
+	waitFor(t, func() bool { return kv.count() == 8 })
+
+	// Ranges ending at 15 and 20 are orphaned, listener rolls back to block 10 and re-scans
+	expected := []fetchRange{{11, 15}, {16, 20}, {21, 25}}
+	requested := client.fetched()
+	for i, r := range expected {
+		if requested[i] != r {
+			t.Fatalf("expected range %v, got %v", r, requested[i])
+		}
+	}
+	if v := testutil.ToFloat64(reorgMetrics.Reorgs.WithLabelValues("1")); v != 1 {
+		t.Fatalf("expected 1 reorg, got %v", v)
+	}
+	if v := testutil.ToFloat64(reorgMetrics.OrphanedMessages.WithLabelValues("1")); v != 1 {
+		t.Fatalf("expected 1 orphaned message, got %v", v)
+	}
+}
+
+func TestListenToEventsRollsBackBlocksReorgedBeforeRestart(t *testing.T) {
+	client := &mockChainClient{
+		head: big.NewInt(20),
+		logs: map[int64][]*DepositLogs{
+			7: {{DestinationID: 2, DepositNonce: 1}},
+		},
+	}
+	kv := &mockBlockstore{}
+	stop := make(chan struct{})
+
+	l := NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(5), big.NewInt(0), time.Millisecond, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+	ch := l.ListenToEvents(big.NewInt(1), 1, kv, stop, make(chan error, 1))
+	if m := <-ch; m.DepositNonce != 1 {
+		t.Fatalf("expected deposit nonce 1, got %d", m.DepositNonce)
+	}
+	waitFor(t, func() bool { return kv.count() == 4 })
+	close(stop)
+	for range ch {
+	}
+
+	// Blocks from 18 are replaced while relayer is down, a new deposit is in block 19
+	client.reorg(18, "b", big.NewInt(25), map[int64][]*DepositLogs{
+		7:  {{DestinationID: 2, DepositNonce: 1}},
+		19: {{DestinationID: 2, DepositNonce: 2}},
+	})
+	id := uint8(1)
+	startBlock, err := blockstore.SetupBlockstore(&config.GeneralChainConfig{Id: &id}, kv, big.NewInt(1), ListenerName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if startBlock.Int64() != 21 {
+		t.Fatalf("expected listener to resume from block 21, got %s", startBlock)
+	}
+	stop = make(chan struct{})
+	defer close(stop)
+	l = NewEVMListener(client, &mockEventHandler{}, common.Address{}, big.NewInt(5), big.NewInt(0), time.Millisecond, newTestReorgMetrics(), metrics.NewChainStateMetrics())
+	ch = l.ListenToEvents(startBlock, 1, kv, stop, make(chan error, 1))
+	if m := <-ch; m.DepositNonce != 2 {
+		t.Fatalf("expected deposit nonce 2, got %d", m.DepositNonce)
+	}
+
+	// Checkpointed range ending at 20 is orphaned, listener rolls back to block 15 and re-scans
+	waitFor(t, func() bool { return len(client.fetched()) == 2 })
+	expected := []fetchRange{{16, 20}, {21, 25}}
+	requested := client.fetched()
+	for i, r := range expected {
+		if requested[i] != r {
+			t.Fatalf("expected range %v, got %v", r, requested[i])
+		}
+	}
+}
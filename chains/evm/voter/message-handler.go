@@ -92,64 +92,43 @@ func (mh *EVMMessageHandler) RegisterMessageHandler(address common.Address, hand
 }
 
 func ERC20MessageHandler(m *relayer.Message, handlerAddr, bridgeAddress common.Address) (Proposer, error) {
-	if len(m.Payload) != 2 {
-		return nil, errors.New("malformed payload. Len  of payload should be 2")
-	}
-	amount, ok := m.Payload[0].([]byte)
-	if !ok {
-		return nil, errors.New("wrong payloads amount format")
-	}
-	recipient, ok := m.Payload[1].([]byte)
-	if !ok {
-		return nil, errors.New("wrong payloads recipient format")
+	payload, err := m.FungibleTransferPayload()
+	if err != nil {
+		return nil, err
 	}
 	var data []byte
-	data = append(data, common.LeftPadBytes(amount, 32)...) // amount (uint256)
-	recipientLen := big.NewInt(int64(len(recipient))).Bytes()
+	data = append(data, common.LeftPadBytes(payload.Amount.Bytes(), 32)...) // amount (uint256)
+	recipientLen := big.NewInt(int64(len(payload.Recipient))).Bytes()
 	data = append(data, common.LeftPadBytes(recipientLen, 32)...) // length of recipient (uint256)
-	data = append(data, recipient...)                             // recipient ([]byte)
+	data = append(data, payload.Recipient...)                     // recipient ([]byte)
 	return NewProposal(m.Source, m.DepositNonce, m.ResourceId, data, handlerAddr, bridgeAddress), nil
 }
 
-func ERC721MessageHandler(msg *relayer.Message, handlerAddr, bridgeAddress common.Address) (*Proposal, error) {
-	if len(msg.Payload) != 3 {
-		return nil, errors.New("malformed payload. Len  of payload should be 3")
-	}
-	tokenID, ok := msg.Payload[0].([]byte)
-	if !ok {
-		return nil, errors.New("wrong payloads tokenID format")
-	}
-	recipient, ok := msg.Payload[1].([]byte)
-	if !ok {
-		return nil, errors.New("wrong payloads recipient format")
-	}
-	metadata, ok := msg.Payload[2].([]byte)
-	if !ok {
-		return nil, errors.New("wrong payloads metadata format")
+func ERC721MessageHandler(msg *relayer.Message, handlerAddr, bridgeAddress common.Address) (Proposer, error) {
+	payload, err := msg.NonFungibleTransferPayload()
+	if err != nil {
+		return nil, err
 	}
 	data := bytes.Buffer{}
-	data.Write(common.LeftPadBytes(tokenID, 32))
-	recipientLen := big.NewInt(int64(len(recipient))).Bytes()
+	data.Write(common.LeftPadBytes(payload.TokenID.Bytes(), 32))
+	recipientLen := big.NewInt(int64(len(payload.Recipient))).Bytes()
 	data.Write(common.LeftPadBytes(recipientLen, 32))
-	data.Write(recipient)
-	metadataLen := big.NewInt(int64(len(metadata))).Bytes()
+	data.Write(payload.Recipient)
+	metadataLen := big.NewInt(int64(len(payload.Metadata))).Bytes()
 	data.Write(common.LeftPadBytes(metadataLen, 32))
-	data.Write(metadata)
+	data.Write(payload.Metadata)
 	return NewProposal(msg.Source, msg.DepositNonce, msg.ResourceId, data.Bytes(), handlerAddr, bridgeAddress), nil
 }
 
-func GenericMessageHandler(msg *relayer.Message, handlerAddr, bridgeAddress common.Address) (*Proposal, error) {
-	if len(msg.Payload) != 1 {
-		return nil, errors.New("malformed payload. Len  of payload should be 1")
-	}
-	metadata, ok := msg.Payload[0].([]byte)
-	if !ok {
-		return nil, errors.New("unable to convert metadata to []byte")
+func GenericMessageHandler(msg *relayer.Message, handlerAddr, bridgeAddress common.Address) (Proposer, error) {
+	payload, err := msg.GenericTransferPayload()
+	if err != nil {
+		return nil, err
 	}
 	data := bytes.Buffer{}
-	metadataLen := big.NewInt(int64(len(metadata))).Bytes()
+	metadataLen := big.NewInt(int64(len(payload.Metadata))).Bytes()
 	data.Write(common.LeftPadBytes(metadataLen, 32)) // length of metadata (uint256)
-	data.Write(metadata)
+	data.Write(payload.Metadata)
 	return NewProposal(msg.Source, msg.DepositNonce, msg.ResourceId, data.Bytes(), handlerAddr, bridgeAddress), nil
 }
 
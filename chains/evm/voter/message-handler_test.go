@@ -0,0 +1,213 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package voter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
+	"github.com/StirNetwork/chainbridge-core/metrics"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func selector(signature string) string {
+	return string(crypto.Keccak256([]byte(signature))[:4])
+}
+
+func newArguments(t *testing.T, argTypes ...string) abi.Arguments {
+	args := make(abi.Arguments, len(argTypes))
+	for i, typ := range argTypes {
+		abiType, err := abi.NewType(typ, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args[i] = abi.Argument{Type: abiType}
+	}
+	return args
+}
+
+// mockBridge emulates bridge contract of the destination chain. Proposal passes after the relayer vote and is executed
+// by the relayer execute transaction.
+type mockBridge struct {
+	t        *testing.T
+	handler  common.Address
+	head     int64
+	status   relayer.ProposalStatus
+	voted    bool
+	votes    int
+	executed [][]byte // data of executed proposals
+}
+
+func (b *mockBridge) LatestBlock() (*big.Int, error) {
+	b.head++
+	return big.NewInt(b.head), nil
+}
+
+func (b *mockBridge) RelayerAddress() common.Address {
+	return common.HexToAddress("0x1")
+}
+
+func (b *mockBridge) GasPrice() (*big.Int, error) {
+	return big.NewInt(100), nil
+}
+
+func (b *mockBridge) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *mockBridge) CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error) {
+	input := callArgs["data"].(hexutil.Bytes)
+	switch string(input[:4]) {
+	case selector("_resourceIDToHandlerAddress(bytes32)"):
+		return newArguments(b.t, "address").Pack(b.handler)
+	case selector("_hasVotedOnProposal(uint72,bytes32,address)"):
+		return newArguments(b.t, "bool").Pack(b.voted)
+	case selector("getProposal(uint8,uint64,bytes32)"):
+		proposalType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+			{Name: "_resourceID", Type: "bytes32"},
+			{Name: "_dataHash", Type: "bytes32"},
+			{Name: "_yesVotes", Type: "address[]"},
+			{Name: "_noVotes", Type: "address[]"},
+			{Name: "_status", Type: "uint8"},
+			{Name: "_proposedBlock", Type: "uint256"},
+		})
+		if err != nil {
+			b.t.Fatal(err)
+		}
+		return abi.Arguments{{Type: proposalType}}.Pack(struct {
+			ResourceID    [32]byte
+			DataHash      [32]byte
+			YesVotes      []common.Address
+			NoVotes       []common.Address
+			Status        uint8
+			ProposedBlock *big.Int
+		}{Status: uint8(b.status), ProposedBlock: big.NewInt(0)})
+	}
+	return nil, errors.New("unexpected contract call")
+}
+
+func (b *mockBridge) SuggestFees(ctx context.Context) (*evmclient.Fees, error) {
+	return &evmclient.Fees{GasPrice: big.NewInt(100)}, nil
+}
+
+func (b *mockBridge) SendWithFees(ctx context.Context, to *common.Address, data []byte, gasLimit uint64, fees *evmclient.Fees) (common.Hash, error) {
+	switch string(data[:4]) {
+	case selector("voteProposal(uint8,uint64,bytes32,bytes32)"):
+		b.votes++
+		b.voted = true
+		b.status = relayer.ProposalStatusPassed
+	case selector("executeProposal(uint8,uint64,bytes,bytes32)"):
+		args, err := newArguments(b.t, "uint8", "uint64", "bytes", "bytes32").Unpack(data[4:])
+		if err != nil {
+			b.t.Fatal(err)
+		}
+		b.executed = append(b.executed, args[2].([]byte))
+		b.status = relayer.ProposalStatusExecuted
+	default:
+		return common.Hash{}, errors.New("unexpected transaction")
+	}
+	return common.Hash{}, nil
+}
+
+func (b *mockBridge) Forget(hash common.Hash) {}
+
+func (b *mockBridge) Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+}
+
+func newTestBridgeVoter(t *testing.T, handler MessageHandlerFunc) (*EVMVoter, *mockBridge) {
+	bridge := &mockBridge{t: t, handler: common.HexToAddress("0x2"), status: relayer.ProposalStatusActive}
+	mh := NewEVMMessageHandler(bridge, common.HexToAddress("0x3"))
+	mh.RegisterMessageHandler(bridge.handler, handler)
+	return NewVoter(mh, bridge, bridge, big.NewInt(100), nil, metrics.NewBridgeMetrics()), bridge
+}
+
+func TestVoteProposalNonFungibleTransfer(t *testing.T) {
+	v, bridge := newTestBridgeVoter(t, ERC721MessageHandler)
+	tokenID := big.NewInt(42)
+	recipient := common.HexToAddress("0x4").Bytes()
+	metadata := []byte("metadata")
+
+	err := v.VoteProposal(context.Background(), &relayer.Message{
+		Source:       1,
+		Destination:  2,
+		DepositNonce: 3,
+		Type:         relayer.NonFungibleTransfer,
+		Payload:      &relayer.NonFungibleTransferPayload{TokenID: tokenID, Recipient: recipient, Metadata: metadata},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bridge.votes != 1 || len(bridge.executed) != 1 {
+		t.Fatalf("expected 1 vote and 1 execution, got %d votes and %d executions", bridge.votes, len(bridge.executed))
+	}
+	var expected []byte
+	expected = append(expected, common.LeftPadBytes(tokenID.Bytes(), 32)...)
+	expected = append(expected, common.LeftPadBytes(big.NewInt(int64(len(recipient))).Bytes(), 32)...)
+	expected = append(expected, recipient...)
+	expected = append(expected, common.LeftPadBytes(big.NewInt(int64(len(metadata))).Bytes(), 32)...)
+	expected = append(expected, metadata...)
+	if !bytes.Equal(bridge.executed[0], expected) {
+		t.Fatalf("unexpected proposal data %x", bridge.executed[0])
+	}
+}
+
+func TestVoteProposalGenericTransfer(t *testing.T) {
+	v, bridge := newTestBridgeVoter(t, GenericMessageHandler)
+	metadata := []byte("metadata")
+
+	err := v.VoteProposal(context.Background(), &relayer.Message{
+		Source:       1,
+		Destination:  2,
+		DepositNonce: 3,
+		Type:         relayer.GenericTransfer,
+		Payload:      &relayer.GenericTransferPayload{Metadata: metadata},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bridge.votes != 1 || len(bridge.executed) != 1 {
+		t.Fatalf("expected 1 vote and 1 execution, got %d votes and %d executions", bridge.votes, len(bridge.executed))
+	}
+	expected := append(common.LeftPadBytes(big.NewInt(int64(len(metadata))).Bytes(), 32), metadata...)
+	if !bytes.Equal(bridge.executed[0], expected) {
+		t.Fatalf("unexpected proposal data %x", bridge.executed[0])
+	}
+}
+
+func TestVoteProposalMalformedPayload(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler MessageHandlerFunc
+		payload relayer.Payload
+	}{
+		{"erc721 missing tokenID", ERC721MessageHandler, &relayer.NonFungibleTransferPayload{Recipient: []byte{2}, Metadata: []byte{3}}},
+		{"erc721 missing recipient", ERC721MessageHandler, &relayer.NonFungibleTransferPayload{TokenID: big.NewInt(1), Metadata: []byte{3}}},
+		{"erc721 wrong payload type", ERC721MessageHandler, &relayer.GenericTransferPayload{Metadata: []byte{3}}},
+		{"generic empty payload", GenericMessageHandler, nil},
+		{"generic wrong payload type", GenericMessageHandler, &relayer.FungibleTransferPayload{Amount: big.NewInt(1), Recipient: []byte{2}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, bridge := newTestBridgeVoter(t, tc.handler)
+
+			err := v.VoteProposal(context.Background(), &relayer.Message{Source: 1, Destination: 2, DepositNonce: 3, Payload: tc.payload})
+			if err == nil {
+				t.Fatal("expected malformed payload error")
+			}
+			if bridge.votes != 0 || len(bridge.executed) != 0 {
+				t.Fatalf("expected no transactions, got %d votes and %d executions", bridge.votes, len(bridge.executed))
+			}
+		})
+	}
+}
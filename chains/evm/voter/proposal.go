@@ -5,10 +5,9 @@ import (
 	"math/big"
 	"strings"
 
-	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
-
 	"github.com/status-im/keycard-go/hexutils"
 
+	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
 	"github.com/StirNetwork/chainbridge-core/relayer"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -17,8 +16,6 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-type TxFabric func(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) evmclient.CommonTransaction
-
 func NewProposal(source uint8, depositNonce uint64, resourceId [32]byte, data []byte, handlerAddress, bridgeAddress common.Address) *Proposal {
 	return &Proposal{
 		Source:         source,
@@ -95,74 +92,44 @@ func (p *Proposal) VotedBy(evmCaller ChainClient, by common.Address) (bool, erro
 	return out0, nil
 }
 
-func (p *Proposal) Execute(client ChainClient, fabric TxFabric) error {
+func (p *Proposal) Execute(ctx context.Context, sender TxSender, fees *evmclient.Fees) (common.Hash, error) {
 	log.Debug().Str("rID", hexutils.BytesToHex(p.ResourceId[:])).Uint64("depositNonce", p.DepositNonce).Msg("Executing proposal")
 	definition := "[{\"inputs\":[{\"internalType\":\"uint8\",\"name\":\"chainID\",\"type\":\"uint8\"},{\"internalType\":\"uint64\",\"name\":\"depositNonce\",\"type\":\"uint64\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"},{\"internalType\":\"bytes32\",\"name\":\"resourceID\",\"type\":\"bytes32\"}],\"name\":\"executeProposal\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
 	a, err := abi.JSON(strings.NewReader(definition))
 	if err != nil {
-		return err // Not sure what status to use here
+		return common.Hash{}, err
 	}
 	input, err := a.Pack("executeProposal", p.Source, p.DepositNonce, p.Data, p.ResourceId)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	gasLimit := uint64(2000000)
-	gp, err := client.GasPrice()
-	if err != nil {
-		return err
-	}
-	client.LockNonce()
-	n, err := client.UnsafeNonce()
-	if err != nil {
-		return err
-	}
-	tx := fabric(n.Uint64(), &p.BridgeAddress, big.NewInt(0), gasLimit, gp, input)
-	hash, err := client.SignAndSendTransaction(context.TODO(), tx)
+	hash, err := sender.SendWithFees(ctx, &p.BridgeAddress, input, gasLimit, fees)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
-	log.Debug().Str("hash", hash.String()).Uint64("nonce", n.Uint64()).Msgf("Executed")
-	err = client.UnsafeIncreaseNonce()
-	if err != nil {
-		return err
-	}
-	client.UnlockNonce()
-	return nil
+	log.Debug().Str("hash", hash.String()).Msgf("Executed")
+	return hash, nil
 }
 
-func (p *Proposal) Vote(client ChainClient, fabric TxFabric) error {
+func (p *Proposal) Vote(ctx context.Context, sender TxSender, fees *evmclient.Fees) (common.Hash, error) {
 	log.Debug().Str("rID", hexutils.BytesToHex(p.ResourceId[:])).Uint64("depositNonce", p.DepositNonce).Uint8("chainID", p.Source).Msg("Voting proposal")
 	definition := "[{\"inputs\":[{\"internalType\":\"uint8\",\"name\":\"chainID\",\"type\":\"uint8\"},{\"internalType\":\"uint64\",\"name\":\"depositNonce\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"resourceID\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"dataHash\",\"type\":\"bytes32\"}],\"name\":\"voteProposal\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
 	a, err := abi.JSON(strings.NewReader(definition))
 	if err != nil {
-		return err // Not sure what status to use here
+		return common.Hash{}, err
 	}
 	input, err := a.Pack("voteProposal", p.Source, p.DepositNonce, p.ResourceId, p.GetDataHash())
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	gasLimit := uint64(1000000)
-	gp, err := client.GasPrice()
-	if err != nil {
-		return err
-	}
-	client.LockNonce()
-	n, err := client.UnsafeNonce()
-	if err != nil {
-		return err
-	}
-	tx := fabric(n.Uint64(), &p.BridgeAddress, big.NewInt(0), gasLimit, gp, input)
-	hash, err := client.SignAndSendTransaction(context.TODO(), tx)
-	if err != nil {
-		return err
-	}
-	log.Debug().Str("hash", hash.String()).Uint64("nonce", n.Uint64()).Msgf("Voted")
-	err = client.UnsafeIncreaseNonce()
+	hash, err := sender.SendWithFees(ctx, &p.BridgeAddress, input, gasLimit, fees)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
-	client.UnlockNonce()
-	return nil
+	log.Debug().Str("hash", hash.String()).Msgf("Voted")
+	return hash, nil
 }
 
 // GetDataHash constructs and returns proposal data hash
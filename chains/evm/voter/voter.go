@@ -5,36 +5,51 @@ package voter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
-
+	"github.com/StirNetwork/chainbridge-core/metrics"
 	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/rs/zerolog/log"
 )
 
+// BlockRetryInterval is how often voter polls for transaction receipts and proposal status
 var BlockRetryInterval = time.Second * 5
 
+// TxRetryLimit is how many times failed vote or execute transaction is resent
+var TxRetryLimit = 3
+
+// GasPriceBumpPercent is the gas price or fee cap and priority fee increase applied to every resent transaction
+var GasPriceBumpPercent int64 = 20
+
+var ErrProposalTimeout = errors.New("proposal timeout exceeded")
+
 type ChainClient interface {
 	LatestBlock() (*big.Int, error)
-	SignAndSendTransaction(ctx context.Context, tx evmclient.CommonTransaction) (common.Hash, error)
 	RelayerAddress() common.Address
 	CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error)
-	UnsafeNonce() (*big.Int, error)
-	LockNonce()
-	UnlockNonce()
-	UnsafeIncreaseNonce() error
-	GasPrice() (*big.Int, error)
 	ChainID(ctx context.Context) (*big.Int, error)
 }
 
+// TxSender sends transactions of the relayer account, see evmclient.TransactionManager
+type TxSender interface {
+	SendWithFees(ctx context.Context, to *common.Address, data []byte, gasLimit uint64, fees *evmclient.Fees) (common.Hash, error)
+	SuggestFees(ctx context.Context) (*evmclient.Fees, error)
+	Receipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	Forget(hash common.Hash)
+}
+
 type Proposer interface {
 	Status(client ChainClient) (relayer.ProposalStatus, error)
 	VotedBy(client ChainClient, by common.Address) (bool, error)
-	Execute(client ChainClient, fabric TxFabric) error
-	Vote(client ChainClient, fabric TxFabric) error
+	Execute(ctx context.Context, sender TxSender, fees *evmclient.Fees) (common.Hash, error)
+	Vote(ctx context.Context, sender TxSender, fees *evmclient.Fees) (common.Hash, error)
 }
 
 type MessageHandler interface {
@@ -42,76 +57,251 @@ type MessageHandler interface {
 }
 
 type EVMVoter struct {
-	stop   <-chan struct{}
-	mh     MessageHandler
-	client ChainClient
-	fabric TxFabric
+	mh              MessageHandler
+	client          ChainClient
+	sender          TxSender
+	proposalTimeout *big.Int
+	maxGasPrice     *big.Int
+	bridgeMetrics   *metrics.BridgeMetrics
 }
 
-func NewVoter(mh MessageHandler, client ChainClient, fabric TxFabric) *EVMVoter {
+// NewVoter creates voter that gives up on proposal if it was not executed within proposalTimeout blocks.
+// Fees of resent transactions never exceed maxGasPrice, nil maxGasPrice is not applied.
+func NewVoter(mh MessageHandler, client ChainClient, sender TxSender, proposalTimeout *big.Int, maxGasPrice *big.Int, bridgeMetrics *metrics.BridgeMetrics) *EVMVoter {
 	return &EVMVoter{
-		mh:     mh,
-		client: client,
-		fabric: fabric,
+		mh:              mh,
+		client:          client,
+		sender:          sender,
+		proposalTimeout: proposalTimeout,
+		maxGasPrice:     maxGasPrice,
+		bridgeMetrics:   bridgeMetrics,
 	}
 }
 
-func (w *EVMVoter) VoteProposal(m *relayer.Message) error {
+// VoteProposal votes for the proposal, waits for it to pass and executes it. Every transaction is confirmed by its receipt
+// and failed transactions are resent with bumped fees. Returns ErrProposalTimeout if proposal is not finished in proposalTimeout blocks
+// and ctx error if ctx is done first.
+func (w *EVMVoter) VoteProposal(ctx context.Context, m *relayer.Message) error {
 	prop, err := w.mh.HandleMessage(m)
 	if err != nil {
+		w.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, "malformed_message")
 		return err
 	}
-	ps, err := prop.Status(w.client)
+	head, err := w.client.LatestBlock()
 	if err != nil {
-		log.Error().Err(err).Msgf("error getting proposal status %+v", prop)
+		return err
 	}
+	deadline := new(big.Int).Add(head, w.proposalTimeout)
 
-	votedByCurrentExecutor, err := prop.VotedBy(w.client, w.client.RelayerAddress())
+	ps, err := prop.Status(w.client)
 	if err != nil {
-		return err
+		return fmt.Errorf("error getting proposal status: %w", err)
+	}
+	if ps == relayer.ProposalStatusCanceled || ps == relayer.ProposalStatusExecuted {
+		log.Debug().Str("voter", w.client.RelayerAddress().String()).Uint64("nonce", m.DepositNonce).Msgf("proposal status %s", relayer.StatusMap[ps])
+		return nil
 	}
 
-	if votedByCurrentExecutor || ps == relayer.ProposalStatusPassed || ps == relayer.ProposalStatusCanceled || ps == relayer.ProposalStatusExecuted {
-		if ps == relayer.ProposalStatusPassed {
-			// We should not vote for this proposal but it is ready to be executed
-			err = prop.Execute(w.client, w.fabric)
+	var votedAt time.Time
+	if ps != relayer.ProposalStatusPassed {
+		votedByCurrentExecutor, err := prop.VotedBy(w.client, w.client.RelayerAddress())
+		if err != nil {
+			return err
+		}
+		if !votedByCurrentExecutor {
+			err = w.transact(ctx, prop.Vote, w.voted(prop), deadline)
 			if err != nil {
-				log.Error().Err(err).Msgf("Executing failed")
-				return err
+				w.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, failureReason("vote", err))
+				return fmt.Errorf("voting on proposal %d from chain %d failed: %w", m.DepositNonce, m.Source, err)
 			}
-			return nil
-		} else {
-			log.Debug().Bool("voted", votedByCurrentExecutor).Str("voter", w.client.RelayerAddress().String()).Msgf("proposal status %s", relayer.StatusMap[ps])
+			votedAt = time.Now()
+			w.bridgeMetrics.ProposalVoted(m.Source, m.Destination, m.ResourceId)
+		}
+		ps, err = w.waitForStatus(ctx, prop, deadline)
+		if err != nil {
+			w.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, failureReason("wait", err))
+			return fmt.Errorf("waiting for proposal %d from chain %d failed: %w", m.DepositNonce, m.Source, err)
+		}
+		if ps != relayer.ProposalStatusPassed {
+			log.Debug().Str("voter", w.client.RelayerAddress().String()).Uint64("nonce", m.DepositNonce).Msgf("proposal status %s", relayer.StatusMap[ps])
 			return nil
 		}
 	}
-	err = prop.Vote(w.client, w.fabric)
+
+	err = w.transact(ctx, prop.Execute, w.executed(prop), deadline)
 	if err != nil {
-		log.Error().Err(err).Msgf("Voting failed")
-		return err
+		w.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, failureReason("execute", err))
+		return fmt.Errorf("executing proposal %d from chain %d failed: %w", m.DepositNonce, m.Source, err)
 	}
-	// Checking every 5 seconds does proposal is ready to be executed
-	// TODO: somehow update infinity loop to break after some period of time
-	for {
-		select {
-		case <-time.After(BlockRetryInterval):
-			ps, err := prop.Status(w.client)
+	w.bridgeMetrics.ProposalExecuted(m.Source, m.Destination, m.ResourceId)
+	if !votedAt.IsZero() {
+		w.bridgeMetrics.ObserveVoteToExecution(m.Source, m.Destination, m.ResourceId, time.Since(votedAt))
+	}
+	return nil
+}
+
+// failureReason returns metrics failure reason of failed proposal step
+func failureReason(step string, err error) string {
+	if errors.Is(err, ErrProposalTimeout) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "canceled"
+	}
+	return step
+}
+
+type sendTxFunc func(ctx context.Context, sender TxSender, fees *evmclient.Fees) (common.Hash, error)
+
+// transact sends transaction and waits for successful receipt. Fees of the first transaction are derived by sender,
+// failed transaction is resent with bumped fees suggested by sender unless done reports that transaction is not needed
+// anymore, e.g. proposal was executed by other relayer. Transaction that could not be sent is retried after BlockRetryInterval.
+func (w *EVMVoter) transact(ctx context.Context, send sendTxFunc, done func() (bool, error), deadline *big.Int) error {
+	var fees *evmclient.Fees
+	for attempt := 0; attempt <= TxRetryLimit; attempt++ {
+		if attempt > 0 {
+			ok, err := done()
 			if err != nil {
-				log.Error().Err(err).Msgf("error getting proposal status %+v", prop)
-				return err
+				log.Error().Err(err).Msg("Unable to check proposal state")
 			}
-			if ps == relayer.ProposalStatusPassed {
-				err = prop.Execute(w.client, w.fabric)
+			if ok {
+				return nil
+			}
+			if fees == nil {
+				fees, err = w.sender.SuggestFees(ctx)
 				if err != nil {
-					log.Error().Err(err).Msgf("Executing failed")
-					return err
+					log.Error().Err(err).Int("attempt", attempt).Msg("Unable to get transaction fees")
+					if err := sleep(ctx, BlockRetryInterval); err != nil {
+						return err
+					}
+					continue
 				}
-				return nil
+			}
+			fees = bumpFees(fees, w.maxGasPrice)
+		}
+		hash, err := send(ctx, w.sender, fees)
+		if err != nil {
+			log.Error().Err(err).Int("attempt", attempt).Msg("Sending transaction failed")
+			if err := sleep(ctx, BlockRetryInterval); err != nil {
+				return err
 			}
 			continue
-		case <-w.stop:
+		}
+		receipt, err := w.waitForReceipt(ctx, hash, deadline)
+		if err != nil {
+			return err
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful {
 			return nil
+		}
+		log.Error().Str("hash", hash.String()).Int("attempt", attempt).Msg("Transaction reverted")
+	}
+	return fmt.Errorf("transaction failed after %d attempts", TxRetryLimit+1)
+}
+
+// voted reports whether relayer vote is not needed anymore
+func (w *EVMVoter) voted(prop Proposer) func() (bool, error) {
+	return func() (bool, error) {
+		ps, err := prop.Status(w.client)
+		if err != nil {
+			return false, err
+		}
+		if ps == relayer.ProposalStatusPassed || ps == relayer.ProposalStatusExecuted || ps == relayer.ProposalStatusCanceled {
+			return true, nil
+		}
+		return prop.VotedBy(w.client, w.client.RelayerAddress())
+	}
+}
+
+// executed reports whether proposal execution is not needed anymore
+func (w *EVMVoter) executed(prop Proposer) func() (bool, error) {
+	return func() (bool, error) {
+		ps, err := prop.Status(w.client)
+		if err != nil {
+			return false, err
+		}
+		return ps == relayer.ProposalStatusExecuted || ps == relayer.ProposalStatusCanceled, nil
+	}
+}
+
+// waitForReceipt polls for transaction receipt until deadline block or until ctx is done,
+// sender forgets transaction if it gives up
+func (w *EVMVoter) waitForReceipt(ctx context.Context, hash common.Hash, deadline *big.Int) (*types.Receipt, error) {
+	for {
+		receipt, err := w.sender.Receipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			log.Error().Err(err).Str("hash", hash.String()).Msg("Unable to get transaction receipt")
+		}
+		err = w.checkDeadline(deadline)
+		if err == nil {
+			err = sleep(ctx, BlockRetryInterval)
+		}
+		if err != nil {
+			w.sender.Forget(hash)
+			return nil, err
+		}
+	}
+}
 
+// waitForStatus polls proposal status until it is passed, executed or cancelled, until deadline block or until ctx is done
+func (w *EVMVoter) waitForStatus(ctx context.Context, prop Proposer, deadline *big.Int) (relayer.ProposalStatus, error) {
+	for {
+		ps, err := prop.Status(w.client)
+		if err != nil {
+			log.Error().Err(err).Msgf("error getting proposal status %+v", prop)
+		} else if ps == relayer.ProposalStatusPassed || ps == relayer.ProposalStatusExecuted || ps == relayer.ProposalStatusCanceled {
+			return ps, nil
+		}
+		err = w.checkDeadline(deadline)
+		if err == nil {
+			err = sleep(ctx, BlockRetryInterval)
 		}
+		if err != nil {
+			return relayer.ProposalStatusInactive, err
+		}
+	}
+}
+
+// sleep waits for d, returns error if ctx is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (w *EVMVoter) checkDeadline(deadline *big.Int) error {
+	head, err := w.client.LatestBlock()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to get latest block")
+		return nil
+	}
+	if head.Cmp(deadline) == 1 {
+		return fmt.Errorf("%w: deadline block %s, latest block %s", ErrProposalTimeout, deadline.String(), head.String())
+	}
+	return nil
+}
+
+// bumpFees increases gas price or fee cap and priority fee by GasPriceBumpPercent up to maxGasPrice
+func bumpFees(fees *evmclient.Fees, maxGasPrice *big.Int) *evmclient.Fees {
+	bumped := &evmclient.Fees{GasPrice: bumpGasPrice(fees.GasPrice, maxGasPrice)}
+	if fees.GasTipCap != nil {
+		bumped.GasTipCap = bumpGasPrice(fees.GasTipCap, bumped.GasPrice)
+	}
+	return bumped
+}
+
+// bumpGasPrice increases gas price by GasPriceBumpPercent, bumped price never exceeds maxGasPrice unless it is nil
+func bumpGasPrice(gasPrice *big.Int, maxGasPrice *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(gasPrice, big.NewInt(100+GasPriceBumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	if maxGasPrice != nil && bumped.Cmp(maxGasPrice) == 1 {
+		return new(big.Int).Set(maxGasPrice)
 	}
+	return bumped
 }
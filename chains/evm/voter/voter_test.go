@@ -0,0 +1,317 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package voter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
+	"github.com/StirNetwork/chainbridge-core/metrics"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMain(m *testing.M) {
+	BlockRetryInterval = time.Millisecond
+	os.Exit(m.Run())
+}
+
+type mockChainClient struct {
+	head     int64
+	receipts map[common.Hash]*types.Receipt
+	fees     *evmclient.Fees // fees suggested by sender, legacy gas price 100 if nil
+}
+
+func (c *mockChainClient) LatestBlock() (*big.Int, error) {
+	c.head++
+	return big.NewInt(c.head), nil
+}
+
+func (c *mockChainClient) SendWithFees(ctx context.Context, to *common.Address, data []byte, gasLimit uint64, fees *evmclient.Fees) (common.Hash, error) {
+	return common.Hash{}, nil
+}
+
+func (c *mockChainClient) SuggestFees(ctx context.Context) (*evmclient.Fees, error) {
+	if c.fees != nil {
+		return c.fees, nil
+	}
+	return &evmclient.Fees{GasPrice: big.NewInt(100)}, nil
+}
+
+func (c *mockChainClient) Forget(hash common.Hash) {}
+
+func (c *mockChainClient) Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	r, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return r, nil
+}
+
+func (c *mockChainClient) RelayerAddress() common.Address {
+	return common.HexToAddress("0x1")
+}
+
+func (c *mockChainClient) CallContract(ctx context.Context, callArgs map[string]interface{}, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (c *mockChainClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+// mockProposer moves to the next status from statuses on every successful transaction
+type mockProposer struct {
+	client   *mockChainClient
+	statuses []relayer.ProposalStatus
+	voted    bool
+	reverts  int // number of transactions that revert before the first successful one
+	failures int // number of transactions that fail to be sent before the first sent one
+	fees     []*evmclient.Fees
+	votes    int
+	executes int
+}
+
+func (p *mockProposer) Status(client ChainClient) (relayer.ProposalStatus, error) {
+	return p.statuses[0], nil
+}
+
+func (p *mockProposer) VotedBy(client ChainClient, by common.Address) (bool, error) {
+	return p.voted, nil
+}
+
+func (p *mockProposer) Execute(ctx context.Context, sender TxSender, fees *evmclient.Fees) (common.Hash, error) {
+	p.executes++
+	if p.failures > 0 {
+		p.failures--
+		return common.Hash{}, errors.New("sending failed")
+	}
+	return p.send(fees), nil
+}
+
+func (p *mockProposer) Vote(ctx context.Context, sender TxSender, fees *evmclient.Fees) (common.Hash, error) {
+	p.votes++
+	return p.send(fees), nil
+}
+
+func (p *mockProposer) send(fees *evmclient.Fees) common.Hash {
+	p.fees = append(p.fees, fees)
+	hash := common.BigToHash(big.NewInt(int64(len(p.fees))))
+	status := types.ReceiptStatusSuccessful
+	if p.reverts > 0 {
+		p.reverts--
+		status = types.ReceiptStatusFailed
+	} else if len(p.statuses) > 1 {
+		p.statuses = p.statuses[1:]
+	}
+	p.client.receipts[hash] = &types.Receipt{Status: status}
+	return hash
+}
+
+type mockMessageHandler struct {
+	prop Proposer
+}
+
+func (mh *mockMessageHandler) HandleMessage(m *relayer.Message) (Proposer, error) {
+	return mh.prop, nil
+}
+
+func newTestVoter(prop *mockProposer, timeout int64) *EVMVoter {
+	return NewVoter(&mockMessageHandler{prop: prop}, prop.client, prop.client, big.NewInt(timeout), nil, metrics.NewBridgeMetrics())
+}
+
+func TestVoteProposalVotesAndExecutes(t *testing.T) {
+	client := &mockChainClient{receipts: make(map[common.Hash]*types.Receipt)}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusActive, relayer.ProposalStatusPassed, relayer.ProposalStatusExecuted},
+	}
+
+	err := newTestVoter(prop, 100).VoteProposal(context.Background(), &relayer.Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prop.votes != 1 || prop.executes != 1 {
+		t.Fatalf("expected 1 vote and 1 execution, got %d votes and %d executions", prop.votes, prop.executes)
+	}
+}
+
+func TestVoteProposalResendsRevertedTxWithBumpedGasPrice(t *testing.T) {
+	client := &mockChainClient{receipts: make(map[common.Hash]*types.Receipt)}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusPassed, relayer.ProposalStatusExecuted},
+		reverts:  1,
+	}
+
+	err := newTestVoter(prop, 100).VoteProposal(context.Background(), &relayer.Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prop.votes != 0 || prop.executes != 2 {
+		t.Fatalf("expected 0 votes and 2 executions, got %d votes and %d executions", prop.votes, prop.executes)
+	}
+	// Fees of the first transaction are derived by sender
+	if prop.fees[0] != nil || prop.fees[1].GasPrice.Int64() != 120 || prop.fees[1].GasTipCap != nil {
+		t.Fatalf("expected sender fees and gas price 120, got %v and %+v", prop.fees[0], prop.fees[1])
+	}
+}
+
+func TestVoteProposalResendsRevertedTxWithBumpedDynamicFees(t *testing.T) {
+	client := &mockChainClient{
+		receipts: make(map[common.Hash]*types.Receipt),
+		fees:     &evmclient.Fees{GasTipCap: big.NewInt(10), GasPrice: big.NewInt(210)},
+	}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusPassed, relayer.ProposalStatusExecuted},
+		reverts:  2,
+	}
+
+	err := newTestVoter(prop, 100).VoteProposal(context.Background(), &relayer.Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prop.fees[0] != nil {
+		t.Fatalf("expected sender fees on the first attempt, got %+v", prop.fees[0])
+	}
+	for i, expected := range []*evmclient.Fees{{GasTipCap: big.NewInt(12), GasPrice: big.NewInt(252)}, {GasTipCap: big.NewInt(14), GasPrice: big.NewInt(302)}} {
+		fees := prop.fees[i+1]
+		if fees.GasTipCap.Cmp(expected.GasTipCap) != 0 || fees.GasPrice.Cmp(expected.GasPrice) != 0 {
+			t.Fatalf("expected tip %s and fee cap %s on retry %d, got %s and %s", expected.GasTipCap, expected.GasPrice, i+1, fees.GasTipCap, fees.GasPrice)
+		}
+	}
+}
+
+func TestVoteProposalBumpedFeesDoNotExceedMaxGasPrice(t *testing.T) {
+	client := &mockChainClient{
+		receipts: make(map[common.Hash]*types.Receipt),
+		fees:     &evmclient.Fees{GasTipCap: big.NewInt(100), GasPrice: big.NewInt(100)},
+	}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusPassed, relayer.ProposalStatusExecuted},
+		reverts:  2,
+	}
+
+	err := NewVoter(&mockMessageHandler{prop: prop}, client, client, big.NewInt(100), big.NewInt(130), metrics.NewBridgeMetrics()).VoteProposal(context.Background(), &relayer.Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []int64{120, 130} {
+		fees := prop.fees[i+1]
+		if fees.GasPrice.Int64() != expected || fees.GasTipCap.Int64() != expected {
+			t.Fatalf("expected tip and fee cap %d on retry %d, got %s and %s", expected, i+1, fees.GasTipCap, fees.GasPrice)
+		}
+	}
+}
+
+func TestVoteProposalGivesUpAfterRetryLimit(t *testing.T) {
+	client := &mockChainClient{receipts: make(map[common.Hash]*types.Receipt)}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusPassed},
+		reverts:  TxRetryLimit + 1,
+	}
+
+	err := newTestVoter(prop, 100).VoteProposal(context.Background(), &relayer.Message{})
+	if err == nil {
+		t.Fatal("expected error after retry limit")
+	}
+	if prop.executes != TxRetryLimit+1 {
+		t.Fatalf("expected %d executions, got %d", TxRetryLimit+1, prop.executes)
+	}
+}
+
+func TestVoteProposalWaitsBeforeResendingFailedTx(t *testing.T) {
+	defer func(interval time.Duration) { BlockRetryInterval = interval }(BlockRetryInterval)
+	BlockRetryInterval = time.Hour
+	client := &mockChainClient{receipts: make(map[common.Hash]*types.Receipt)}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusPassed},
+		failures: TxRetryLimit + 1,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	err := newTestVoter(prop, 100).VoteProposal(ctx, &relayer.Message{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context error while waiting to resend, got %v", err)
+	}
+	if prop.executes != 1 {
+		t.Fatalf("expected failed transaction not to be resent before retry interval, got %d executions", prop.executes)
+	}
+}
+
+func TestVoteProposalTimesOut(t *testing.T) {
+	client := &mockChainClient{receipts: make(map[common.Hash]*types.Receipt)}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusActive},
+		voted:    true,
+	}
+
+	err := newTestVoter(prop, 5).VoteProposal(context.Background(), &relayer.Message{})
+	if !errors.Is(err, ErrProposalTimeout) {
+		t.Fatalf("expected proposal timeout error, got %v", err)
+	}
+	if prop.votes != 0 || prop.executes != 0 {
+		t.Fatalf("expected no transactions, got %d votes and %d executions", prop.votes, prop.executes)
+	}
+}
+
+func TestVoteProposalStopsWhenContextIsDone(t *testing.T) {
+	client := &mockChainClient{receipts: make(map[common.Hash]*types.Receipt)}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusActive},
+		voted:    true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := newTestVoter(prop, 1000000).VoteProposal(ctx, &relayer.Message{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+}
+
+func TestVoteProposalRecordsMetrics(t *testing.T) {
+	client := &mockChainClient{receipts: make(map[common.Hash]*types.Receipt)}
+	prop := &mockProposer{
+		client:   client,
+		statuses: []relayer.ProposalStatus{relayer.ProposalStatusActive, relayer.ProposalStatusPassed, relayer.ProposalStatusExecuted},
+	}
+	bridgeMetrics := metrics.NewBridgeMetrics()
+	v := NewVoter(&mockMessageHandler{prop: prop}, client, client, big.NewInt(100), nil, bridgeMetrics)
+
+	err := v.VoteProposal(context.Background(), &relayer.Message{Source: 7, Destination: 8, ResourceId: [32]byte{7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := []string{"7", "8", fmt.Sprintf("%x", [32]byte{7})}
+	if voted := testutil.ToFloat64(bridgeMetrics.ProposalsVoted.WithLabelValues(labels...)); voted != 1 {
+		t.Fatalf("expected 1 voted proposal, got %v", voted)
+	}
+	if executed := testutil.ToFloat64(bridgeMetrics.ProposalsExecuted.WithLabelValues(labels...)); executed != 1 {
+		t.Fatalf("expected 1 executed proposal, got %v", executed)
+	}
+
+	prop = &mockProposer{client: client, statuses: []relayer.ProposalStatus{relayer.ProposalStatusActive}, voted: true}
+	v = NewVoter(&mockMessageHandler{prop: prop}, client, client, big.NewInt(5), nil, bridgeMetrics)
+	_ = v.VoteProposal(context.Background(), &relayer.Message{Source: 7, Destination: 8, ResourceId: [32]byte{7}})
+	if failures := testutil.ToFloat64(bridgeMetrics.Failures.WithLabelValues(append(labels, "timeout")...)); failures != 1 {
+		t.Fatalf("expected 1 timeout failure, got %v", failures)
+	}
+}
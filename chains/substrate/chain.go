@@ -1,8 +1,10 @@
 package substrate
 
 import (
+	"context"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/StirNetwork/chainbridge-core/blockstore"
 	"github.com/StirNetwork/chainbridge-core/config"
@@ -11,63 +13,74 @@ import (
 )
 
 type ProposalVoter interface {
-	VoteProposal(message *relayer.Message) error
+	VoteProposal(ctx context.Context, message *relayer.Message) error
 }
 
 type EventListener interface {
-	ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message
+	ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueReaderWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message
+	// LastProcessed returns the last processed block and when it was processed
+	LastProcessed() (*big.Int, time.Time)
+	// Name identifies checkpoints of listener in blockstore
+	Name() string
 }
 
 type SubstrateChain struct {
-	chainID  uint8
-	stop     chan<- struct{}
-	listener EventListener
-	writer   ProposalVoter
-	kvdb     blockstore.KeyValueReaderWriter
-	config   *config.SharedSubstrateConfig
+	chainID    uint8
+	listener   EventListener
+	writer     ProposalVoter
+	kvdb       blockstore.KeyValueReaderWriter
+	config     *config.SharedSubstrateConfig
+	client     ChainClient
+	processors []relayer.MessageProcessor
 }
 
-func NewSubstrateChain(listener EventListener, writer ProposalVoter, kvdb blockstore.KeyValueReaderWriter, chainID uint8, config *config.SharedSubstrateConfig) *SubstrateChain {
+func NewSubstrateChain(listener EventListener, writer ProposalVoter, kvdb blockstore.KeyValueReaderWriter, chainID uint8, config *config.SharedSubstrateConfig, client ChainClient, processors ...relayer.MessageProcessor) *SubstrateChain {
 	return &SubstrateChain{
-		listener: listener,
-		writer:   writer,
-		kvdb:     kvdb,
-		chainID:  chainID,
-		config:   config,
+		listener:   listener,
+		writer:     writer,
+		kvdb:       kvdb,
+		chainID:    chainID,
+		config:     config,
+		client:     client,
+		processors: processors,
 	}
 }
 
-func (c *SubstrateChain) PollEvents(stop <-chan struct{}, sysErr chan<- error, eventsChan chan *relayer.Message) {
+func (c *SubstrateChain) PollEvents(ctx context.Context, sysErr chan<- error, eventsChan chan *relayer.Message) {
 	log.Info().Msg("Polling Blocks...")
 	// Handler chain specific configs and flags
-	//b, err := blockstore.GetLastStoredBlock(c.kvdb, c.chainID)
-	block, err := blockstore.SetupBlockstore(&c.config.GeneralChainConfig, c.kvdb, c.config.StartBlock)
+	block, err := blockstore.SetupBlockstore(&c.config.GeneralChainConfig, c.kvdb, c.config.StartBlock, c.listener.Name())
 	if err != nil {
 		sysErr <- fmt.Errorf("error %w on getting last stored block", err)
 		return
 	}
-	ech := c.listener.ListenToEvents(block, c.chainID, c.kvdb, stop, sysErr)
-	for {
-		select {
-		case <-stop:
-			return
-		case newEvent := <-ech:
-			// Here we can place middlewares for custom logic?
-			eventsChan <- newEvent
-			continue
-		}
+	ech := c.listener.ListenToEvents(block, c.chainID, c.kvdb, ctx.Done(), sysErr)
+	// Listener closes ech once it stops after ctx is done, messages it already read are still passed to relayer
+	for newEvent := range ech {
+		// Here we can place middlewares for custom logic?
+		eventsChan <- newEvent
 	}
+	log.Info().Uint8("chainID", c.chainID).Msg("Chain listener stopped")
 }
 
-func (c *SubstrateChain) Write(message *relayer.Message) error {
-	return c.writer.VoteProposal(message)
-
+// Write votes for proposal of message, it gives up once ctx is done
+func (c *SubstrateChain) Write(ctx context.Context, message *relayer.Message) error {
+	return c.writer.VoteProposal(ctx, message)
 }
 
 func (c *SubstrateChain) ChainID() uint8 {
 	return c.chainID
 }
 
-func (c *SubstrateChain) Stop() {
-	close(c.stop)
+func (c *SubstrateChain) WorkerConfig() relayer.WorkerConfig {
+	return relayer.WorkerConfig{
+		Concurrency:  c.config.GeneralChainConfig.WorkerConcurrency,
+		QueueSize:    c.config.GeneralChainConfig.WorkerQueueSize,
+		OrderByNonce: c.config.GeneralChainConfig.OrderByNonce,
+	}
+}
+
+// MessageProcessors returns processors of messages written to the chain
+func (c *SubstrateChain) MessageProcessors() []relayer.MessageProcessor {
+	return c.processors
 }
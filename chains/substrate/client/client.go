@@ -0,0 +1,273 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/StirNetwork/chainbridge-core/chains/substrate"
+	"github.com/StirNetwork/chainbridge-core/chains/substrate/writer"
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client"
+	"github.com/centrifuge/go-substrate-rpc-client/rpc/author"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/types"
+	"github.com/rs/zerolog/log"
+)
+
+var ErrTerminated = errors.New("client terminated")
+
+// SubstrateClient is the RPC connection to a substrate chain. It implements both listener.SubstrateReader and writer.Voter.
+type SubstrateClient struct {
+	api         *gsrpc.SubstrateAPI
+	meta        types.Metadata         // Latest chain metadata
+	metaLock    sync.RWMutex           // Lock metadata for updates, allows concurrent reads
+	genesisHash types.Hash             // Chain genesis hash
+	key         *signature.KeyringPair // Keyring used for signing
+	nonce       types.U32              // Latest account nonce
+	nonceLock   sync.Mutex             // Locks nonce for updates
+}
+
+func NewSubstrateClient(url string, key *signature.KeyringPair) (*SubstrateClient, error) {
+	log.Info().Str("url", url).Msg("Connecting to substrate chain...")
+	api, err := gsrpc.NewSubstrateAPI(url)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := api.RPC.State.GetMetadataLatest()
+	if err != nil {
+		return nil, err
+	}
+	genesisHash, err := api.RPC.Chain.GetBlockHash(0)
+	if err != nil {
+		return nil, err
+	}
+	return &SubstrateClient{
+		api:         api,
+		meta:        *meta,
+		genesisHash: genesisHash,
+		key:         key,
+	}, nil
+}
+
+func (c *SubstrateClient) GetHeaderLatest() (*types.Header, error) {
+	return c.api.RPC.Chain.GetHeaderLatest()
+}
+
+// LatestBlock returns number of the latest block, it gives up once ctx is done
+func (c *SubstrateClient) LatestBlock(ctx context.Context) (*big.Int, error) {
+	var header *types.Header
+	err := withContext(ctx, func() (err error) {
+		header, err = c.GetHeaderLatest()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(int64(header.Number)), nil
+}
+
+func (c *SubstrateClient) GetBlockHash(blockNumber uint64) (types.Hash, error) {
+	return c.api.RPC.Chain.GetBlockHash(blockNumber)
+}
+
+// GetBlockEvents queries System.Events storage at the provided block and decodes all event records into target
+func (c *SubstrateClient) GetBlockEvents(hash types.Hash, target interface{}) error {
+	meta := c.GetMetadata()
+	key, err := types.CreateStorageKey(&meta, "System", "Events", nil, nil)
+	if err != nil {
+		return err
+	}
+	var records types.EventRecordsRaw
+	_, err = c.api.RPC.State.GetStorage(key, &records, hash)
+	if err != nil {
+		return err
+	}
+	return records.DecodeEventRecords(&meta, target)
+}
+
+// UpdateMetatdata fetches the latest metadata from the chain. It should be called after a runtime upgrade.
+func (c *SubstrateClient) UpdateMetatdata() error {
+	meta, err := c.api.RPC.State.GetMetadataLatest()
+	if err != nil {
+		return err
+	}
+	c.metaLock.Lock()
+	c.meta = *meta
+	c.metaLock.Unlock()
+	return nil
+}
+
+func (c *SubstrateClient) GetMetadata() (meta types.Metadata) {
+	c.metaLock.RLock()
+	meta = c.meta
+	c.metaLock.RUnlock()
+	return meta
+}
+
+func (c *SubstrateClient) GetVoterAccountID() types.AccountID {
+	return types.NewAccountID(c.key.PublicKey)
+}
+
+// ResolveResourceId returns the method registered for the resource in ChainBridge.Resources storage
+func (c *SubstrateClient) ResolveResourceId(id [32]byte) (string, error) {
+	var res []byte
+	exists, err := c.queryStorage(writer.BridgeStoragePrefix, "Resources", id[:], nil, &res)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("resource %x not found on chain", id)
+	}
+	return string(res), nil
+}
+
+// KeypairLoaded reports if relayer keypair was loaded from keystore
+func (c *SubstrateClient) KeypairLoaded() bool {
+	return c.key != nil
+}
+
+// IsRelayer reports if relayer account is registered in ChainBridge.Relayers storage, it gives up once ctx is done
+func (c *SubstrateClient) IsRelayer(ctx context.Context) (bool, error) {
+	var isRelayer types.Bool
+	err := withContext(ctx, func() error {
+		_, err := c.queryStorage(writer.BridgeStoragePrefix, "Relayers", c.key.PublicKey, nil, &isRelayer)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return bool(isRelayer), nil
+}
+
+// GetProposalStatus queries ChainBridge.Votes storage for the proposal. Returns false if proposal was not voted yet.
+func (c *SubstrateClient) GetProposalStatus(sourceID, proposalBytes []byte) (bool, *substrate.VoteState, error) {
+	voteRes := &substrate.VoteState{}
+	exists, err := c.queryStorage(writer.BridgeStoragePrefix, "Votes", sourceID, proposalBytes, voteRes)
+	if err != nil {
+		return false, nil, err
+	}
+	return exists, voteRes, nil
+}
+
+// SubmitTx constructs and submits an extrinsic signed by the relayer key. It blocks until the extrinsic is included in a block
+// or ctx is done.
+func (c *SubstrateClient) SubmitTx(ctx context.Context, method string, args ...interface{}) error {
+	log.Debug().Str("method", method).Msg("Submitting substrate call...")
+	meta := c.GetMetadata()
+
+	call, err := types.NewCall(&meta, method, args...)
+	if err != nil {
+		return err
+	}
+	ext := types.NewExtrinsic(call)
+
+	rv, err := c.api.RPC.State.GetRuntimeVersionLatest()
+	if err != nil {
+		return err
+	}
+
+	c.nonceLock.Lock()
+	latestNonce, err := c.getLatestNonce()
+	if err != nil {
+		c.nonceLock.Unlock()
+		return err
+	}
+	if latestNonce > c.nonce {
+		c.nonce = latestNonce
+	}
+
+	o := types.SignatureOptions{
+		BlockHash:          c.genesisHash,
+		Era:                types.ExtrinsicEra{IsMortalEra: false},
+		GenesisHash:        c.genesisHash,
+		Nonce:              types.NewUCompactFromUInt(uint64(c.nonce)),
+		SpecVersion:        rv.SpecVersion,
+		Tip:                types.NewUCompactFromUInt(0),
+		TransactionVersion: rv.TransactionVersion,
+	}
+	err = ext.Sign(*c.key, o)
+	if err != nil {
+		c.nonceLock.Unlock()
+		return err
+	}
+
+	sub, err := c.api.RPC.Author.SubmitAndWatchExtrinsic(ext)
+	if err != nil {
+		c.nonceLock.Unlock()
+		return err
+	}
+	c.nonce++
+	c.nonceLock.Unlock()
+	defer sub.Unsubscribe()
+
+	return c.watchSubmission(ctx, sub)
+}
+
+func (c *SubstrateClient) watchSubmission(ctx context.Context, sub *author.ExtrinsicStatusSubscription) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s", ErrTerminated, ctx.Err())
+		case status := <-sub.Chan():
+			switch {
+			case status.IsInBlock:
+				log.Debug().Str("block", status.AsInBlock.Hex()).Msg("Extrinsic included in block")
+				return nil
+			case status.IsRetracted:
+				return fmt.Errorf("extrinsic retracted: %s", status.AsRetracted.Hex())
+			case status.IsDropped:
+				return fmt.Errorf("extrinsic dropped from network")
+			case status.IsInvalid:
+				return fmt.Errorf("extrinsic invalid")
+			}
+		case err := <-sub.Err():
+			return err
+		}
+	}
+}
+
+// getLatestNonce returns the nonce of the relayer account stored in System.Account
+func (c *SubstrateClient) getLatestNonce() (types.U32, error) {
+	var acct types.AccountInfo
+	exists, err := c.queryStorage("System", "Account", c.key.PublicKey, nil, &acct)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+	return acct.Nonce, nil
+}
+
+// queryStorage performs a storage lookup. Arguments may be nil, result must be a pointer.
+func (c *SubstrateClient) queryStorage(prefix, method string, arg1, arg2 []byte, result interface{}) (bool, error) {
+	meta := c.GetMetadata()
+	key, err := types.CreateStorageKey(&meta, prefix, method, arg1, arg2)
+	if err != nil {
+		return false, err
+	}
+	return c.api.RPC.State.GetStorageLatest(key, result)
+}
+
+// withContext runs f until it returns or ctx is done. RPC client does not accept ctx, so f keeps running in the background
+// after ctx is done and its results are dropped.
+func withContext(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
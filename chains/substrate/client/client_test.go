@@ -0,0 +1,259 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/StirNetwork/chainbridge-core/chains/substrate"
+	"github.com/centrifuge/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/types"
+)
+
+var testPublicKey = bytes.Repeat([]byte{0xd4}, 32)
+
+type mockChainSrv struct {
+	header types.Header
+}
+
+func (s *mockChainSrv) GetBlockHash(height *uint64) string {
+	return types.Hash{0x01}.Hex()
+}
+
+func (s *mockChainSrv) GetHeader(hash *string) types.Header {
+	return s.header
+}
+
+type mockStateSrv struct {
+	metadata string
+	storage  map[string]string
+}
+
+func (s *mockStateSrv) GetMetadata(hash *string) string {
+	return s.metadata
+}
+
+func (s *mockStateSrv) GetStorage(key string, hash *string) string {
+	return s.storage[key]
+}
+
+// testMetadata extends exemplary substrate metadata with the ChainBridge storage items
+func testMetadata(t *testing.T) *types.Metadata {
+	meta := &types.Metadata{}
+	err := types.DecodeFromHexString(types.ExamplaryMetadataV11SubstrateString, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blake2 := types.StorageHasherV10{IsBlake2_256: true}
+	meta.AsMetadataV11.Modules = append(meta.AsMetadataV11.Modules, types.ModuleMetadataV10{
+		Name:       "ChainBridge",
+		HasStorage: true,
+		Storage: types.StorageMetadataV10{
+			Prefix: "ChainBridge",
+			Items: []types.StorageFunctionMetadataV10{
+				{
+					Name:     "Resources",
+					Modifier: types.StorageFunctionModifierV0{IsOptional: true},
+					Type:     types.StorageFunctionTypeV10{IsMap: true, AsMap: types.MapTypeV10{Hasher: blake2, Key: "ResourceId", Value: "Vec<u8>"}},
+				},
+				{
+					Name:     "Votes",
+					Modifier: types.StorageFunctionModifierV0{IsOptional: true},
+					Type:     types.StorageFunctionTypeV10{IsDoubleMap: true, AsDoubleMap: types.DoubleMapTypeV10{Hasher: blake2, Key2Hasher: blake2, Key1: "ChainId", Key2: "(DepositNonce, Proposal)", Value: "ProposalVotes"}},
+				},
+				{
+					Name:     "Relayers",
+					Modifier: types.StorageFunctionModifierV0{IsDefault: true},
+					Type:     types.StorageFunctionTypeV10{IsMap: true, AsMap: types.MapTypeV10{Hasher: blake2, Key: "AccountId", Value: "bool"}},
+				},
+			},
+		},
+	})
+	return meta
+}
+
+func setupClient(t *testing.T, storage func(meta *types.Metadata) map[string]string) *SubstrateClient {
+	meta := testMetadata(t)
+	metaHex, err := types.EncodeToHexString(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := rpcmocksrv.New()
+	err = s.RegisterName("chain", &mockChainSrv{header: types.ExamplaryHeader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.RegisterName("state", &mockStateSrv{metadata: metaHex, storage: storage(meta)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewSubstrateClient(s.URL, &signature.KeyringPair{PublicKey: testPublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func storageKeyHex(t *testing.T, meta *types.Metadata, prefix, method string, arg1, arg2 []byte) string {
+	key, err := types.CreateStorageKey(meta, prefix, method, arg1, arg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.Hex()
+}
+
+func encodeHex(t *testing.T, value interface{}) string {
+	h, err := types.EncodeToHexString(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestSubstrateClient_GetHeaderLatest(t *testing.T) {
+	c := setupClient(t, func(meta *types.Metadata) map[string]string { return nil })
+
+	header, err := c.GetHeaderLatest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Number != types.ExamplaryHeader.Number {
+		t.Fatalf("expected header number %v, got %v", types.ExamplaryHeader.Number, header.Number)
+	}
+	if c.genesisHash != (types.Hash{0x01}) {
+		t.Fatalf("unexpected genesis hash %s", c.genesisHash.Hex())
+	}
+}
+
+func TestSubstrateClient_LatestBlock(t *testing.T) {
+	c := setupClient(t, func(meta *types.Metadata) map[string]string { return nil })
+
+	block, err := c.LatestBlock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Uint64() != uint64(types.ExamplaryHeader.Number) {
+		t.Fatalf("expected block %v, got %s", types.ExamplaryHeader.Number, block)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.LatestBlock(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+}
+
+func TestSubstrateClient_ResolveResourceId(t *testing.T) {
+	registered := [32]byte{1}
+	c := setupClient(t, func(meta *types.Metadata) map[string]string {
+		return map[string]string{
+			storageKeyHex(t, meta, "ChainBridge", "Resources", registered[:], nil): encodeHex(t, []byte("Erc20.transfer")),
+		}
+	})
+
+	method, err := c.ResolveResourceId(registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != "Erc20.transfer" {
+		t.Fatalf("expected method Erc20.transfer, got %s", method)
+	}
+
+	_, err = c.ResolveResourceId([32]byte{2})
+	if err == nil {
+		t.Fatal("must fail on unregistered resource")
+	}
+}
+
+func TestSubstrateClient_GetProposalStatus(t *testing.T) {
+	srcID := []byte{1}
+	prop := []byte{1, 2, 3}
+	voter := types.NewAccountID(testPublicKey)
+	state := substrate.VoteState{
+		VotesFor:     []types.AccountID{voter},
+		VotesAgainst: []types.AccountID{},
+		Status:       substrate.VoteStatus{IsApproved: true},
+	}
+	c := setupClient(t, func(meta *types.Metadata) map[string]string {
+		return map[string]string{
+			storageKeyHex(t, meta, "ChainBridge", "Votes", srcID, prop): encodeHex(t, state),
+		}
+	})
+
+	exists, res, err := c.GetProposalStatus(srcID, prop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatal("proposal must exist")
+	}
+	if !res.Status.IsApproved || res.Status.IsActive || len(res.VotesFor) != 1 || res.VotesFor[0] != c.GetVoterAccountID() {
+		t.Fatalf("unexpected vote state %+v", res)
+	}
+
+	exists, _, err = c.GetProposalStatus(srcID, []byte{4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("proposal must not exist")
+	}
+}
+
+func TestSubstrateClient_GetLatestNonce(t *testing.T) {
+	account := types.AccountInfo{Nonce: 7}
+	account.Data.Free = types.NewU128(*big.NewInt(0))
+	account.Data.Reserved = types.NewU128(*big.NewInt(0))
+	account.Data.MiscFrozen = types.NewU128(*big.NewInt(0))
+	account.Data.FreeFrozen = types.NewU128(*big.NewInt(0))
+	c := setupClient(t, func(meta *types.Metadata) map[string]string {
+		return map[string]string{
+			storageKeyHex(t, meta, "System", "Account", testPublicKey, nil): encodeHex(t, account),
+		}
+	})
+
+	nonce, err := c.getLatestNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce != 7 {
+		t.Fatalf("expected nonce 7, got %v", nonce)
+	}
+}
+
+func TestSubstrateClient_IsRelayer(t *testing.T) {
+	c := setupClient(t, func(meta *types.Metadata) map[string]string {
+		return map[string]string{
+			storageKeyHex(t, meta, "ChainBridge", "Relayers", testPublicKey, nil): encodeHex(t, types.NewBool(true)),
+		}
+	})
+	if !c.KeypairLoaded() {
+		t.Fatal("expected keypair to be loaded")
+	}
+
+	isRelayer, err := c.IsRelayer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRelayer {
+		t.Fatal("expected registered relayer")
+	}
+
+	c.key = &signature.KeyringPair{PublicKey: bytes.Repeat([]byte{0xd5}, 32)}
+	isRelayer, err = c.IsRelayer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isRelayer {
+		t.Fatal("expected unregistered relayer")
+	}
+}
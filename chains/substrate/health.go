@@ -0,0 +1,49 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package substrate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/StirNetwork/chainbridge-core/health"
+)
+
+// ChainClient is used by SubstrateChain to report chain status
+type ChainClient interface {
+	LatestBlock(ctx context.Context) (*big.Int, error)
+	KeypairLoaded() bool
+	IsRelayer(ctx context.Context) (bool, error)
+}
+
+// Status reports RPC availability, listener progress and whether relayer account is registered on the bridge
+func (c *SubstrateChain) Status(ctx context.Context) health.ChainStatus {
+	status := health.ChainStatus{ChainID: c.chainID}
+	status.LastProcessedBlock, status.LastProcessedAt = c.listener.LastProcessed()
+	if c.client == nil {
+		status.Errors = append(status.Errors, "chain client is not set")
+		return status
+	}
+
+	head, err := c.client.LatestBlock(ctx)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("unable to get latest block: %s", err))
+	} else {
+		status.RPCReachable = true
+		if status.LastProcessedBlock != nil {
+			status.BlocksBehindHead = new(big.Int).Sub(head, status.LastProcessedBlock)
+		}
+	}
+
+	status.KeystoreLoaded = c.client.KeypairLoaded()
+	if !status.KeystoreLoaded {
+		return status
+	}
+	status.IsRelayer, err = c.client.IsRelayer(ctx)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("unable to check relayer registration: %s", err))
+	}
+	return status
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package substrate
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/StirNetwork/chainbridge-core/blockstore"
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+)
+
+type mockListener struct {
+	block *big.Int
+	at    time.Time
+}
+
+func (l *mockListener) ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueReaderWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message {
+	return nil
+}
+
+func (l *mockListener) LastProcessed() (*big.Int, time.Time) {
+	return l.block, l.at
+}
+
+func (l *mockListener) Name() string {
+	return "mock"
+}
+
+type mockChainClient struct {
+	head      *big.Int
+	headErr   error
+	hang      bool // head lookup blocks until ctx is done
+	isRelayer bool
+	keypair   bool
+}
+
+func (c *mockChainClient) LatestBlock(ctx context.Context) (*big.Int, error) {
+	if c.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return c.head, c.headErr
+}
+
+func (c *mockChainClient) KeypairLoaded() bool {
+	return c.keypair
+}
+
+func (c *mockChainClient) IsRelayer(ctx context.Context) (bool, error) {
+	return c.isRelayer, nil
+}
+
+func TestStatus(t *testing.T) {
+	at := time.Now()
+	client := &mockChainClient{head: big.NewInt(110), isRelayer: true, keypair: true}
+	chain := NewSubstrateChain(&mockListener{block: big.NewInt(100), at: at}, nil, nil, 1, &config.SharedSubstrateConfig{}, client)
+
+	status := chain.Status(context.Background())
+	if !status.RPCReachable || !status.KeystoreLoaded || !status.IsRelayer || len(status.Errors) != 0 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.LastProcessedBlock.Int64() != 100 || !status.LastProcessedAt.Equal(at) || status.BlocksBehindHead.Int64() != 10 {
+		t.Fatalf("unexpected listener progress %+v", status)
+	}
+}
+
+func TestStatusUnreachableRPC(t *testing.T) {
+	client := &mockChainClient{headErr: errors.New("connection refused"), keypair: true}
+	chain := NewSubstrateChain(&mockListener{block: big.NewInt(100), at: time.Now()}, nil, nil, 1, &config.SharedSubstrateConfig{}, client)
+
+	status := chain.Status(context.Background())
+	if status.RPCReachable || status.IsRelayer || status.BlocksBehindHead != nil || len(status.Errors) != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestStatusGivesUpWhenContextIsDone(t *testing.T) {
+	client := &mockChainClient{hang: true}
+	chain := NewSubstrateChain(&mockListener{block: big.NewInt(100), at: time.Now()}, nil, nil, 1, &config.SharedSubstrateConfig{}, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	status := chain.Status(ctx)
+	if status.RPCReachable || status.KeystoreLoaded || len(status.Errors) != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
@@ -2,6 +2,7 @@ package listener
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/StirNetwork/chainbridge-core/chains/substrate"
 	"github.com/StirNetwork/chainbridge-core/relayer"
@@ -18,9 +19,10 @@ func FungibleTransferHandler(sourceID uint8, evtI interface{}) (*relayer.Message
 		Destination:  uint8(evt.Destination),
 		DepositNonce: uint64(evt.DepositNonce),
 		ResourceId:   evt.ResourceId,
-		Payload: []interface{}{
-			evt.Amount.Bytes(),
-			[]byte(evt.Recipient),
+		Type:         relayer.FungibleTransfer,
+		Payload: &relayer.FungibleTransferPayload{
+			Amount:    evt.Amount.Int,
+			Recipient: []byte(evt.Recipient),
 		},
 	}, nil
 }
@@ -36,10 +38,11 @@ func NonFungibleTransferHandler(sourceID uint8, evtI interface{}) (*relayer.Mess
 		Destination:  uint8(evt.Destination),
 		DepositNonce: uint64(evt.DepositNonce),
 		ResourceId:   evt.ResourceId,
-		Payload: []interface{}{
-			[]byte(evt.TokenId),
-			[]byte(evt.Recipient),
-			[]byte(evt.Metadata),
+		Type:         relayer.NonFungibleTransfer,
+		Payload: &relayer.NonFungibleTransferPayload{
+			TokenID:   new(big.Int).SetBytes(evt.TokenId),
+			Recipient: []byte(evt.Recipient),
+			Metadata:  []byte(evt.Metadata),
 		},
 	}, nil
 }
@@ -54,8 +57,9 @@ func GenericTransferHandler(sourceID uint8, evtI interface{}) (*relayer.Message,
 		Destination:  uint8(evt.Destination),
 		DepositNonce: uint64(evt.DepositNonce),
 		ResourceId:   evt.ResourceId,
-		Payload: []interface{}{
-			[]byte(evt.Metadata),
+		Type:         relayer.GenericTransfer,
+		Payload: &relayer.GenericTransferPayload{
+			Metadata: []byte(evt.Metadata),
 		},
 	}, nil
 }
@@ -3,6 +3,7 @@ package listener
 import (
 	"errors"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/status-im/keycard-go/hexutils"
@@ -18,6 +19,9 @@ var BlockRetryInterval = time.Second * 5
 
 var ErrBlockNotReady = errors.New("required result to be 32 bytes, but got 0")
 
+// ListenerName identifies checkpoints of substrate event listener in blockstore
+const ListenerName = "events"
+
 type SubstrateReader interface {
 	GetHeaderLatest() (*types.Header, error)
 	GetBlockHash(blockNumber uint64) (types.Hash, error)
@@ -36,6 +40,10 @@ func NewSubstrateListener(client SubstrateReader) *SubstrateListener {
 type SubstrateListener struct {
 	client        SubstrateReader
 	eventHandlers map[relayer.TransferType]EventHandler
+
+	lock            sync.Mutex
+	lastProcessed   *big.Int
+	lastProcessedAt time.Time
 }
 
 func (l *SubstrateListener) RegisterSubscription(tt relayer.TransferType, handler EventHandler) {
@@ -45,9 +53,17 @@ func (l *SubstrateListener) RegisterSubscription(tt relayer.TransferType, handle
 	l.eventHandlers[tt] = handler
 }
 
-func (l *SubstrateListener) ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message {
+// Name identifies checkpoints of listener in blockstore
+func (l *SubstrateListener) Name() string {
+	return ListenerName
+}
+
+func (l *SubstrateListener) ListenToEvents(startBlock *big.Int, chainID uint8, kvrw blockstore.KeyValueReaderWriter, stopChn <-chan struct{}, errChn chan<- error) <-chan *relayer.Message {
 	ch := make(chan *relayer.Message)
+	l.setLastProcessed(nil)
 	go func() {
+		// Closing ch tells reader that listener stopped and no more messages are sent
+		defer close(ch)
 		for {
 			select {
 			case <-stopChn:
@@ -91,10 +107,11 @@ func (l *SubstrateListener) ListenToEvents(startBlock *big.Int, chainID uint8, k
 					// Logging process every 20 blocks to exclude spam
 					log.Debug().Str("block", startBlock.String()).Uint8("chainID", chainID).Msg("Queried block for deposit events")
 				}
-				err = blockstore.StoreBlock(kvrw, startBlock, chainID)
+				err = blockstore.StoreCheckpoint(kvrw, chainID, l.Name(), &blockstore.Checkpoint{Block: new(big.Int).Set(startBlock), Hash: hash[:], Timestamp: time.Now()})
 				if err != nil {
-					log.Error().Str("block", startBlock.String()).Err(err).Msg("Failed to write latest block to blockstore")
+					log.Error().Str("block", startBlock.String()).Err(err).Msg("Failed to write checkpoint to blockstore")
 				}
+				l.setLastProcessed(startBlock)
 				startBlock.Add(startBlock, big.NewInt(1))
 			}
 		}
@@ -102,6 +119,26 @@ func (l *SubstrateListener) ListenToEvents(startBlock *big.Int, chainID uint8, k
 	return ch
 }
 
+// LastProcessed returns the last processed block and when it was processed.
+// Block is nil and time is listener start until the first block is processed.
+func (l *SubstrateListener) LastProcessed() (*big.Int, time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.lastProcessed == nil {
+		return nil, l.lastProcessedAt
+	}
+	return new(big.Int).Set(l.lastProcessed), l.lastProcessedAt
+}
+
+func (l *SubstrateListener) setLastProcessed(block *big.Int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if block != nil {
+		l.lastProcessed = new(big.Int).Set(block)
+	}
+	l.lastProcessedAt = time.Now()
+}
+
 // handleEvents calls the associated handler for all registered event types
 func (l *SubstrateListener) handleEvents(chainID uint8, evts *substrate.Events) ([]*relayer.Message, error) {
 	msgs := make([]*relayer.Message, 0)
@@ -1,13 +1,53 @@
 package substrate
 
-import "github.com/centrifuge/go-substrate-rpc-client/types"
+import (
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/types"
+)
 
 type VoteState struct {
 	VotesFor     []types.AccountID
 	VotesAgainst []types.AccountID
-	Status       struct {
-		IsActive   bool
-		IsApproved bool
-		IsRejected bool
+	Status       VoteStatus
+}
+
+type VoteStatus struct {
+	IsActive   bool
+	IsApproved bool
+	IsRejected bool
+}
+
+// Decode decodes ChainBridge ProposalStatus enum
+func (s *VoteStatus) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		s.IsActive = true
+	case 1:
+		s.IsApproved = true
+	case 2:
+		s.IsRejected = true
+	default:
+		return fmt.Errorf("unrecognized proposal status %v", b)
+	}
+	return nil
+}
+
+// Encode encodes ChainBridge ProposalStatus enum
+func (s VoteStatus) Encode(encoder scale.Encoder) error {
+	switch {
+	case s.IsActive:
+		return encoder.PushByte(0)
+	case s.IsApproved:
+		return encoder.PushByte(1)
+	case s.IsRejected:
+		return encoder.PushByte(2)
 	}
+	return fmt.Errorf("unset proposal status")
 }
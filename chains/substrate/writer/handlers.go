@@ -1,36 +1,45 @@
 package writer
 
 import (
-	"math/big"
-
 	"github.com/StirNetwork/chainbridge-core/relayer"
 	"github.com/centrifuge/go-substrate-rpc-client/types"
 )
 
-func CreateFungibleProposal(m *relayer.Message) []interface{} {
-	bigAmt := big.NewInt(0).SetBytes(m.Payload[0].([]byte))
-	amount := types.NewU128(*bigAmt)
-	recipient := types.NewAccountID(m.Payload[1].([]byte))
+func CreateFungibleProposal(m *relayer.Message) ([]interface{}, error) {
+	payload, err := m.FungibleTransferPayload()
+	if err != nil {
+		return nil, err
+	}
+	amount := types.NewU128(*payload.Amount)
+	recipient := types.NewAccountID(payload.Recipient)
 
 	t := make([]interface{}, 2)
 	t[0] = recipient
 	t[1] = amount
-	return t
+	return t, nil
 }
 
-func CreateNonFungibleProposal(m *relayer.Message) []interface{} {
-	tokenId := types.NewU256(*big.NewInt(0).SetBytes(m.Payload[0].([]byte)))
-	recipient := types.NewAccountID(m.Payload[1].([]byte))
-	metadata := types.Bytes(m.Payload[2].([]byte))
+func CreateNonFungibleProposal(m *relayer.Message) ([]interface{}, error) {
+	payload, err := m.NonFungibleTransferPayload()
+	if err != nil {
+		return nil, err
+	}
+	tokenId := types.NewU256(*payload.TokenID)
+	recipient := types.NewAccountID(payload.Recipient)
+	metadata := types.Bytes(payload.Metadata)
 	t := make([]interface{}, 3)
 	t[0] = recipient
 	t[1] = tokenId
 	t[2] = metadata
-	return t
+	return t, nil
 }
 
-func CreateGenericProposal(m *relayer.Message) []interface{} {
+func CreateGenericProposal(m *relayer.Message) ([]interface{}, error) {
+	payload, err := m.GenericTransferPayload()
+	if err != nil {
+		return nil, err
+	}
 	t := make([]interface{}, 1)
-	t[0] = types.NewHash(m.Payload[0].([]byte))
-	return t
+	t[0] = types.NewHash(payload.Metadata)
+	return t, nil
 }
@@ -2,6 +2,7 @@ package writer
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"time"
 
@@ -19,7 +20,7 @@ var BlockRetryLimit = 5
 var AcknowledgeProposal = BridgePalletName + ".acknowledge_proposal"
 
 type Voter interface {
-	SubmitTx(method string, args ...interface{}) error
+	SubmitTx(ctx context.Context, method string, args ...interface{}) error
 	GetVoterAccountID() types.AccountID
 	GetMetadata() (meta types.Metadata)
 	ResolveResourceId(id [32]byte) (string, error)
@@ -27,7 +28,7 @@ type Voter interface {
 	GetProposalStatus(sourceID, proposalBytes []byte) (bool, *substrate.VoteState, error)
 }
 
-type ProposalHandler func(msg *relayer.Message) []interface{}
+type ProposalHandler func(msg *relayer.Message) ([]interface{}, error)
 type ProposalHandlers map[relayer.TransferType]ProposalHandler
 
 type SubstrateWriter struct {
@@ -47,30 +48,44 @@ func (w *SubstrateWriter) RegisterHandler(t relayer.TransferType, handler Propos
 	w.handlers[t] = handler
 }
 
-func (w *SubstrateWriter) VoteProposal(m *relayer.Message) error {
+// VoteProposal acknowledges proposal of message unless it is complete or already voted. Gives up once ctx is done,
+// or returns the last error once BlockRetryLimit attempts failed so message is retried later.
+func (w *SubstrateWriter) VoteProposal(ctx context.Context, m *relayer.Message) error {
 	handler, ok := w.handlers[m.Type]
 	if !ok {
 		return fmt.Errorf("no corresponding substrate handler found for message type %s", m.Type)
 	}
-	prop, err := w.createProposal(m.Source, m.DepositNonce, m.ResourceId, handler(m)...)
+	args, err := handler(m)
+	if err != nil {
+		return fmt.Errorf("malformed %s message %d from chain %d: %w", m.Type, m.DepositNonce, m.Source, err)
+	}
+	prop, err := w.createProposal(m.Source, m.DepositNonce, m.ResourceId, args...)
 	if err != nil {
 		return fmt.Errorf("failed to construct proposal (chain=%d, name=%v) Error: %w", m.Destination, w.chainID, err)
 	}
 
+	var lastErr error
 	for i := 0; i < BlockRetryLimit; i++ {
 		// Ensure we only submit a vote if the proposal hasn't completed
 		valid, reason, err := w.proposalValid(prop)
 		if err != nil {
-			time.Sleep(BlockRetryInterval)
+			log.Error().Err(err).Msg("Failed to get proposal status")
+			lastErr = fmt.Errorf("getting proposal status: %w", err)
+			if err := sleep(ctx, BlockRetryInterval); err != nil {
+				return err
+			}
 			continue
 		}
 
 		// If active submit call, otherwise skip it. Retry on failure.
 		if valid {
-			err = w.client.SubmitTx(AcknowledgeProposal, prop.DepositNonce, prop.SourceId, prop.ResourceId, prop.Call)
+			err = w.client.SubmitTx(ctx, AcknowledgeProposal, prop.DepositNonce, prop.SourceId, prop.ResourceId, prop.Call)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to execute extrinsic")
-				time.Sleep(BlockRetryInterval)
+				lastErr = fmt.Errorf("submitting vote: %w", err)
+				if err := sleep(ctx, BlockRetryInterval); err != nil {
+					return err
+				}
 				continue
 			}
 			return nil
@@ -79,7 +94,7 @@ func (w *SubstrateWriter) VoteProposal(m *relayer.Message) error {
 			return nil
 		}
 	}
-	return nil
+	return fmt.Errorf("voting on proposal %d from chain %d failed after %d attempts: %w", m.DepositNonce, m.Source, BlockRetryLimit, lastErr)
 }
 
 func (w *SubstrateWriter) proposalValid(prop *SubstrateProposal) (bool, string, error) {
@@ -140,6 +155,16 @@ func (w *SubstrateWriter) createProposal(sourceChain uint8, depositNonce uint64,
 	}, nil
 }
 
+// sleep waits for d, returns error if ctx is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
 func containsVote(votes []types.AccountID, voter types.AccountID) bool {
 	for _, v := range votes {
 		if bytes.Equal(v[:], voter[:]) {
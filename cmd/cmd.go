@@ -1,20 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"math/big"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/StirNetwork/chainbridge-core/blockstore"
 	"github.com/StirNetwork/chainbridge-core/chains/evm"
 	evmCLI "github.com/StirNetwork/chainbridge-core/chains/evm/cli"
-	"github.com/StirNetwork/chainbridge-core/chains/evm/evmclient"
-	"github.com/StirNetwork/chainbridge-core/chains/evm/evmtransaction"
-	"github.com/StirNetwork/chainbridge-core/chains/evm/listener"
-	"github.com/StirNetwork/chainbridge-core/chains/evm/voter"
+	"github.com/StirNetwork/chainbridge-core/chains/substrate"
+	substrateClient "github.com/StirNetwork/chainbridge-core/chains/substrate/client"
+	substrateListener "github.com/StirNetwork/chainbridge-core/chains/substrate/listener"
+	"github.com/StirNetwork/chainbridge-core/chains/substrate/writer"
 	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/StirNetwork/chainbridge-core/crypto/sr25519"
+	"github.com/StirNetwork/chainbridge-core/health"
+	"github.com/StirNetwork/chainbridge-core/keystore"
 	"github.com/StirNetwork/chainbridge-core/lvldb"
+	"github.com/StirNetwork/chainbridge-core/messagestore"
+	"github.com/StirNetwork/chainbridge-core/metrics"
 	"github.com/StirNetwork/chainbridge-core/relayer"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -42,62 +50,83 @@ func init() {
 }
 
 func Execute() {
-	rootCMD.AddCommand(runCMD, evmCLI.EvmRootCLI)
+	rootCMD.AddCommand(runCMD, quarantineCMD, evmCLI.EvmRootCLI)
 	if err := rootCMD.Execute(); err != nil {
 		log.Fatal().Err(err).Msg("failed to execute root cmd")
 	}
 }
 
+// Run starts relayer and blocks until termination signal or relayer error. On either chain listeners are stopped,
+// in-flight proposals are given shutdown_timeout to finish and blockstore is closed. Returned error reports any failure.
 func Run() error {
-	errChn := make(chan error)
-	stopChn := make(chan struct{})
-
 	db, err := lvldb.NewLvlDB(viper.GetString(config.BlockstoreFlagName))
 	if err != nil {
-		panic(err)
+		return err
 	}
-
-	// ===== BSC setup =====
-	// bsc is evm compatible. so we can utilize evm module
-	bscClient := evmclient.NewEVMClient()
-	err = bscClient.Configurate(viper.GetString(config.ConfigFlagName), "bsc.json")
+	runErr := run(db)
+	err = db.Close()
 	if err != nil {
-		panic(err)
+		log.Error().Err(err).Msg("failed to close blockstore")
+		return firstError(runErr, err)
 	}
+	if runErr == nil {
+		log.Info().Msg("relayer stopped")
+	}
+	return runErr
+}
 
-	bscConfig := bscClient.GetConfig()
-	bscEventHandler := listener.NewETHEventHandler(common.HexToAddress(bscConfig.SharedEVMConfig.Bridge), bscClient)
-	bscEventHandler.RegisterEventHandler(bscConfig.SharedEVMConfig.Erc20Handler, listener.Erc20EventHandler)
-	bscListener := listener.NewEVMListener(bscClient, bscEventHandler, common.HexToAddress(bscConfig.SharedEVMConfig.Bridge))
-
-	bscMessageHandler := voter.NewEVMMessageHandler(bscClient, common.HexToAddress(bscConfig.SharedEVMConfig.Bridge))
-	bscMessageHandler.RegisterMessageHandler(common.HexToAddress(bscConfig.SharedEVMConfig.Erc20Handler), voter.ERC20MessageHandler)
-	bscVoter := voter.NewVoter(bscMessageHandler, bscClient, evmtransaction.NewTransaction)
-
-	bscChain := evm.NewEVMChain(bscListener, bscVoter, db, *bscConfig.SharedEVMConfig.GeneralChainConfig.Id, &bscConfig.SharedEVMConfig)
+func run(db *lvldb.LVLDB) error {
+	errChn := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// ===== Shiden setup =====
-	// sdn is evm compatible. so we can utilize evm module
-	sdnClient := evmclient.NewEVMClient()
-	err = sdnClient.Configurate(viper.GetString(config.ConfigFlagName), "sdn.json")
+	configuration, err := config.GetConfig(viper.GetString(config.ConfigFlagName))
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	sdnConfig := sdnClient.GetConfig()
-	sdnEventHandler := listener.NewETHEventHandler(common.HexToAddress(sdnConfig.SharedEVMConfig.Bridge), sdnClient)
-	sdnEventHandler.RegisterEventHandler(sdnConfig.SharedEVMConfig.Erc20Handler, listener.Erc20EventHandler)
-	sdnListener := listener.NewEVMListener(sdnClient, sdnEventHandler, common.HexToAddress(sdnConfig.SharedEVMConfig.Bridge))
+	relayer.RegisterProcessorFactory(relayer.TransferLimitsProcessorType, relayer.NewTransferLimitsProcessorFactory(db))
 
-	sdnMessageHandler := voter.NewEVMMessageHandler(sdnClient, common.HexToAddress(sdnConfig.SharedEVMConfig.Bridge))
-	sdnMessageHandler.RegisterMessageHandler(common.HexToAddress(sdnConfig.SharedEVMConfig.Erc20Handler), voter.ERC20MessageHandler)
-	sdnVoter := voter.NewVoter(sdnMessageHandler, sdnClient, evmtransaction.NewTransaction)
+	chains := []relayer.RelayedChain{}
+	for _, chainConfig := range configuration.ChainConfigs {
+		switch chainConfig["type"] {
+		case config.EVMType:
+			chain, err := evm.SetupDefaultEVMChain(chainConfig, db)
+			if err != nil {
+				return err
+			}
+			chains = append(chains, chain)
+		case config.SubstrateType:
+			chain, err := setupSubstrateChain(chainConfig, db)
+			if err != nil {
+				return err
+			}
+			chains = append(chains, chain)
+		default:
+			return fmt.Errorf("type '%v' not recognized", chainConfig["type"])
+		}
+	}
 
-	sdnChain := evm.NewEVMChain(sdnListener, sdnVoter, db, *sdnConfig.SharedEVMConfig.GeneralChainConfig.Id, &sdnConfig.SharedEVMConfig)
+	var server *metrics.Server
+	if viper.GetBool(config.MetricsFlagName) || viper.GetBool(config.HealthFlagName) {
+		server = metrics.NewServer(viper.GetString(config.MetricsAddressFlagName))
+		if viper.GetBool(config.MetricsFlagName) {
+			server.HandleMetrics()
+		}
+		if viper.GetBool(config.HealthFlagName) {
+			checker := health.NewChecker(healthChains(chains), viper.GetDuration(config.StallThresholdFlagName))
+			server.Handle("/health", checker.HealthHandler())
+			server.Handle("/ready", checker.ReadyHandler())
+		}
+		err = server.Start()
+		if err != nil {
+			return fmt.Errorf("failed to start HTTP server: %w", err)
+		}
+	}
 
-	r := relayer.NewRelayer([]relayer.RelayedChain{bscChain, sdnChain})
+	r := relayer.NewRelayer(chains, messagestore.NewMessageStore(db))
 
-	go r.Start(stopChn, errChn)
+	go r.Start(ctx, errChn)
 
 	sysErr := make(chan os.Signal, 1)
 	signal.Notify(sysErr,
@@ -106,13 +135,106 @@ func Run() error {
 		syscall.SIGHUP,
 		syscall.SIGQUIT)
 
+	var runErr error
 	select {
 	case err := <-errChn:
 		log.Error().Err(err).Msg("failed to listen and serve")
-		close(stopChn)
-		return err
+		runErr = err
 	case sig := <-sysErr:
 		log.Info().Msgf("terminating got ` [%v] signal", sig)
-		return nil
 	}
+
+	// Chains may still report errors while stopping, nobody else reads errChn from now on
+	go func() {
+		for err := range errChn {
+			log.Error().Err(err).Msg("error while shutting down")
+		}
+	}()
+	cancel()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), viper.GetDuration(config.ShutdownTimeoutFlagName))
+	defer cancelShutdown()
+
+	err = r.Stop(shutdownCtx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to stop relayer, pending messages are replayed on next start")
+		runErr = firstError(runErr, err)
+	}
+	if server != nil {
+		err = server.Stop(shutdownCtx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to stop HTTP server")
+			runErr = firstError(runErr, err)
+		}
+	}
+	return runErr
+}
+
+func firstError(first, next error) error {
+	if first != nil {
+		return first
+	}
+	return next
+}
+
+// healthChains returns chains able to report their status
+func healthChains(chains []relayer.RelayedChain) []health.Chain {
+	healthChains := []health.Chain{}
+	for _, c := range chains {
+		if hc, ok := c.(health.Chain); ok {
+			healthChains = append(healthChains, hc)
+		} else {
+			log.Warn().Uint8("chainID", c.ChainID()).Msg("Chain does not report status, it is not included in health checks")
+		}
+	}
+	return healthChains
+}
+
+// setupSubstrateChain builds SubstrateChain from a raw chain config entry with
+// handlers registered for all supported transfer types
+func setupSubstrateChain(rawConfig map[string]interface{}, db blockstore.KeyValueReaderWriter) (*substrate.SubstrateChain, error) {
+	cfg, err := config.NewSubstrateConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	generalConfig := cfg.GeneralChainConfig
+	kp, err := keystore.KeypairFromAddress(generalConfig.From, keystore.SubChain, generalConfig.KeystorePath, generalConfig.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	krp := kp.(*sr25519.Keypair).AsKeyringPair()
+
+	client, err := substrateClient.NewSubstrateClient(generalConfig.Endpoint, krp)
+	if err != nil {
+		return nil, err
+	}
+
+	if generalConfig.LatestBlock {
+		header, err := client.GetHeaderLatest()
+		if err != nil {
+			return nil, err
+		}
+		cfg.StartBlock = big.NewInt(int64(header.Number))
+	}
+
+	subListener := substrateListener.NewSubstrateListener(client)
+	subListener.RegisterSubscription(relayer.FungibleTransfer, substrateListener.FungibleTransferHandler)
+	subListener.RegisterSubscription(relayer.NonFungibleTransfer, substrateListener.NonFungibleTransferHandler)
+	subListener.RegisterSubscription(relayer.GenericTransfer, substrateListener.GenericTransferHandler)
+
+	processors := []relayer.MessageProcessor{}
+	for _, pc := range generalConfig.Processors {
+		p, err := relayer.NewMessageProcessor(pc.Type, pc.Params)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, p)
+	}
+
+	subWriter := writer.NewSubstrateWriter(*generalConfig.Id, client)
+	subWriter.RegisterHandler(relayer.FungibleTransfer, writer.CreateFungibleProposal)
+	subWriter.RegisterHandler(relayer.NonFungibleTransfer, writer.CreateNonFungibleProposal)
+	subWriter.RegisterHandler(relayer.GenericTransfer, writer.CreateGenericProposal)
+
+	return substrate.NewSubstrateChain(subListener, subWriter, db, *generalConfig.Id, cfg, client, processors...), nil
 }
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/StirNetwork/chainbridge-core/config"
+	"github.com/StirNetwork/chainbridge-core/lvldb"
+	"github.com/StirNetwork/chainbridge-core/messagestore"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	quarantineCMD = &cobra.Command{
+		Use:   "quarantine",
+		Short: "Manage quarantined messages",
+		Long: "Manage messages quarantined by message processors, like transfers over limits. " +
+			"Relayer must be stopped as it holds the blockstore lock, approved messages are relayed on its next start.",
+	}
+	quarantineListCMD = &cobra.Command{
+		Use:   "list",
+		Short: "List quarantined messages",
+		Long:  "List quarantined messages with the reason they were quarantined",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMessageStore(cmd, listQuarantined)
+		},
+	}
+	quarantineApproveCMD = &cobra.Command{
+		Use:   "approve",
+		Short: "Approve quarantined message",
+		Long:  "Approve quarantined message so it is relayed on the next relayer start",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, _ := cmd.Flags().GetUint8(sourceFlagName)
+			destination, _ := cmd.Flags().GetUint8(destinationFlagName)
+			nonce, _ := cmd.Flags().GetUint64(nonceFlagName)
+			return withMessageStore(cmd, func(s *messagestore.MessageStore) error {
+				err := s.ApproveMessage(source, destination, nonce)
+				if err != nil {
+					return err
+				}
+				log.Info().Uint8("src", source).Uint8("dst", destination).Uint64("nonce", nonce).Msg("Message approved")
+				return nil
+			})
+		},
+	}
+)
+
+var (
+	sourceFlagName      = "source"
+	destinationFlagName = "destination"
+	nonceFlagName       = "nonce"
+)
+
+func init() {
+	// Flag is not bound to viper, binding it would override blockstore flag of run command
+	quarantineCMD.PersistentFlags().String(config.BlockstoreFlagName, "./lvldbdata", "Specify path for blockstore")
+
+	quarantineApproveCMD.Flags().Uint8(sourceFlagName, 0, "Source chain ID of message")
+	quarantineApproveCMD.Flags().Uint8(destinationFlagName, 0, "Destination chain ID of message")
+	quarantineApproveCMD.Flags().Uint64(nonceFlagName, 0, "Deposit nonce of message")
+	_ = quarantineApproveCMD.MarkFlagRequired(sourceFlagName)
+	_ = quarantineApproveCMD.MarkFlagRequired(destinationFlagName)
+	_ = quarantineApproveCMD.MarkFlagRequired(nonceFlagName)
+
+	quarantineCMD.AddCommand(quarantineListCMD, quarantineApproveCMD)
+}
+
+func withMessageStore(cmd *cobra.Command, f func(s *messagestore.MessageStore) error) error {
+	path, _ := cmd.Flags().GetString(config.BlockstoreFlagName)
+	db, err := lvldb.NewLvlDB(path)
+	if err != nil {
+		return fmt.Errorf("failed to open blockstore, relayer must be stopped: %w", err)
+	}
+	err = f(messagestore.NewMessageStore(db))
+	return firstError(err, db.Close())
+}
+
+func listQuarantined(s *messagestore.MessageStore) error {
+	msgs, err := s.MessagesByStatus(relayer.MessageStatusQuarantined)
+	if err != nil {
+		return err
+	}
+	for _, m := range msgs {
+		out, err := json.Marshal(struct {
+			Source       uint8
+			Destination  uint8
+			DepositNonce uint64
+			ResourceID   string
+			Type         relayer.TransferType
+			Reason       string
+		}{m.Message.Source, m.Message.Destination, m.Message.DepositNonce, fmt.Sprintf("%x", m.Message.ResourceId), m.Message.Type, m.Reason})
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	}
+	return nil
+}
@@ -2,13 +2,78 @@ package config
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/viper"
 )
 
+// Supported chain types
+const (
+	EVMType       = "evm"
+	SubstrateType = "substrate"
+)
+
+// Config is the relayer configuration. Every entry of ChainConfigs is a raw chain config
+// that is decoded by the chain module matching its "type" field.
+type Config struct {
+	ChainConfigs []map[string]interface{} `mapstructure:"chains"`
+}
+
+// GetConfig reads relayer configuration from path. Path can either be a JSON file
+// listing chains under the "chains" key or a directory where each JSON file describes a single chain.
+func GetConfig(path string) (*Config, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config path %s, error: %w", path, err)
+	}
+	if !info.IsDir() {
+		return readConfigFile(path)
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory %s, error: %w", path, err)
+	}
+	config := &Config{}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		v := viper.New()
+		v.SetConfigFile(filepath.Join(path, f.Name()))
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read in the config file %s, error: %w", f.Name(), err)
+		}
+		config.ChainConfigs = append(config.ChainConfigs, v.AllSettings())
+	}
+	if len(config.ChainConfigs) == 0 {
+		return nil, fmt.Errorf("no chain configs found in %s", path)
+	}
+	return config, nil
+}
+
+func readConfigFile(path string) (*Config, error) {
+	config := &Config{}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read in the config file, error: %w", err)
+	}
+	if err := v.Unmarshal(config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config into struct, error: %w", err)
+	}
+	if len(config.ChainConfigs) == 0 {
+		return nil, fmt.Errorf("no chain configs found in %s", path)
+	}
+	return config, nil
+}
+
 type GeneralChainConfig struct {
 	Name           string `mapstructure:"name"`
 	Id             *uint8 `mapstructure:"id"`
+	Type           string `mapstructure:"type"`
 	Endpoint       string `mapstructure:"endpoint"`
 	From           string `mapstructure:"from"`
 	KeystorePath   string
@@ -16,6 +81,20 @@ type GeneralChainConfig struct {
 	BlockstorePath string
 	FreshStart     bool
 	LatestBlock    bool
+
+	// Workers writing messages to the chain, unset values fall back to relayer defaults
+	WorkerConcurrency int  `mapstructure:"workerConcurrency"`
+	WorkerQueueSize   int  `mapstructure:"workerQueueSize"`
+	OrderByNonce      bool `mapstructure:"orderByNonce"`
+
+	// Processors of messages written to the chain, run in listed order
+	Processors []ProcessorConfig `mapstructure:"processors"`
+}
+
+// ProcessorConfig configures message processor of Type, all other fields of the config entry are passed to the processor
+type ProcessorConfig struct {
+	Type   string                 `mapstructure:"type"`
+	Params map[string]interface{} `mapstructure:",remain"`
 }
 
 func (c *GeneralChainConfig) Validate() error {
@@ -34,6 +113,14 @@ func (c *GeneralChainConfig) Validate() error {
 	if c.From == "" {
 		return fmt.Errorf("required field chain.From empty for chain %v", *c.Id)
 	}
+	if c.WorkerConcurrency < 0 || c.WorkerQueueSize < 0 {
+		return fmt.Errorf("workerConcurrency and workerQueueSize can not be negative for chain %v", *c.Id)
+	}
+	for i, p := range c.Processors {
+		if p.Type == "" {
+			return fmt.Errorf("required field type of processor %d empty for chain %v", i, *c.Id)
+		}
+	}
 	return nil
 }
 
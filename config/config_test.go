@@ -1,6 +1,9 @@
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -40,6 +43,14 @@ func TestValidateConfig(t *testing.T) {
 		From:     "",
 	}
 
+	negativeWorkers := GeneralChainConfig{
+		Name:              "chain",
+		Id:                &id,
+		Endpoint:          "endpoint",
+		From:              "0x0",
+		WorkerConcurrency: -1,
+	}
+
 	err := valid.Validate()
 	if err != nil {
 		t.Fatal(err)
@@ -64,4 +75,106 @@ func TestValidateConfig(t *testing.T) {
 	if err == nil {
 		t.Fatalf("must require from field, %v", err)
 	}
+
+	err = negativeWorkers.Validate()
+	if err == nil {
+		t.Fatal("must reject negative worker concurrency")
+	}
+}
+
+func TestGetConfigFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.json")
+	data := []byte(`{"chains": [{"type": "evm", "name": "evm1", "id": 1}, {"type": "substrate", "name": "sub1", "id": 2}]}`)
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := GetConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.ChainConfigs) != 2 {
+		t.Fatalf("expected 2 chain configs, got %d", len(cfg.ChainConfigs))
+	}
+	if cfg.ChainConfigs[0]["type"] != EVMType || cfg.ChainConfigs[1]["type"] != SubstrateType {
+		t.Fatalf("unexpected chain types %v, %v", cfg.ChainConfigs[0]["type"], cfg.ChainConfigs[1]["type"])
+	}
+}
+
+func TestGetConfigFromDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"evm1.json":  `{"type": "evm", "name": "evm1", "id": 1}`,
+		"evm2.json":  `{"type": "evm", "name": "evm2", "id": 2}`,
+		"readme.txt": `not a config`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := GetConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.ChainConfigs) != 2 {
+		t.Fatalf("expected 2 chain configs, got %d", len(cfg.ChainConfigs))
+	}
+}
+
+func TestGetConfigWithoutChains(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = GetConfig(dir)
+	if err == nil {
+		t.Fatal("must fail on directory without chain configs")
+	}
+}
+
+func TestGetConfigWithProcessors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.json")
+	data := []byte(`{"chains": [{"type": "substrate", "name": "sub1", "id": 1, "endpoint": "endpoint", "from": "0x0",
+		"processors": [{"type": "adjustDecimals", "decimals": {"1": 18, "2": 6}}]}]}`)
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := GetConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subCfg, err := NewSubstrateConfig(cfg.ChainConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	processors := subCfg.GeneralChainConfig.Processors
+	if len(processors) != 1 || processors[0].Type != "adjustDecimals" {
+		t.Fatalf("unexpected processors %+v", processors)
+	}
+	decimals, ok := processors[0].Params["decimals"].(map[string]interface{})
+	if !ok || len(decimals) != 2 {
+		t.Fatalf("unexpected processor params %+v", processors[0].Params)
+	}
 }
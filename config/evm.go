@@ -3,21 +3,44 @@ package config
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/StirNetwork/chainbridge-core/chains/evm/calls/consts"
 )
 
+// Transaction types that can be set in chain config, empty type is chosen automatically from head block baseFee
+const (
+	LegacyTxType     = "legacy"
+	DynamicFeeTxType = "dynamic"
+)
+
+// Gas pricers that can be set in chain config, node gas pricer is used by default
+const (
+	StaticGasPricer        = "static"
+	NodeGasPricer          = "node"
+	PercentileGasPricer    = "percentile"
+	EthGasStationGasPricer = "ethgasstation"
+)
+
 type SharedEVMConfig struct {
 	GeneralChainConfig GeneralChainConfig
 	Bridge             string
 	Erc20Handler       string
 	Erc721Handler      string
 	GenericHandler     string
+	GasPricer          string
+	GasPrice           *big.Int
+	GasPricePercentile int
+	MinGasPrice        *big.Int
 	MaxGasPrice        *big.Int
 	GasMultiplier      *big.Float
 	GasLimit           *big.Int
 	StartBlock         *big.Int
 	BlockConfirmations *big.Int
+	BlockRange         *big.Int
+	BlockRetryInterval time.Duration
+	ProposalTimeout    *big.Int
+	TxType             string
 }
 
 type RawSharedEVMConfig struct {
@@ -26,11 +49,19 @@ type RawSharedEVMConfig struct {
 	Erc20Handler       string  `mapstructure:"erc20Handler"`
 	Erc721Handler      string  `mapstructure:"erc721Handler"`
 	GenericHandler     string  `mapstructure:"genericHandler"`
+	GasPricer          string  `mapstructure:"gasPricer"`
+	GasPrice           int64   `mapstructure:"gasPrice"`
+	GasPricePercentile int     `mapstructure:"gasPricePercentile"` // 0 uses default percentile
+	MinGasPrice        int64   `mapstructure:"minGasPrice"`
 	MaxGasPrice        int64   `mapstructure:"maxGasPrice"`
 	GasMultiplier      float64 `mapstructure:"gasMultiplier"`
 	GasLimit           int64   `mapstructure:"gasLimit"`
 	StartBlock         int64   `mapstructure:"startBlock"`
-	BlockConfirmations int64   `mapstructure:"blockConfirmations"`
+	BlockConfirmations *int64  `mapstructure:"blockConfirmations"` // nil uses default, 0 processes blocks as soon as they are mined
+	BlockRange         int64   `mapstructure:"blockRange"`
+	BlockRetryInterval uint64  `mapstructure:"blockRetryInterval"`
+	ProposalTimeout    int64   `mapstructure:"proposalTimeout"`
+	TxType             string  `mapstructure:"txType"`
 }
 
 func (c *RawSharedEVMConfig) Validate() error {
@@ -40,6 +71,28 @@ func (c *RawSharedEVMConfig) Validate() error {
 	if c.Bridge == "" {
 		return fmt.Errorf("required field chain.Bridge empty for chain %v", *c.Id)
 	}
+	if c.TxType != "" && c.TxType != LegacyTxType && c.TxType != DynamicFeeTxType {
+		return fmt.Errorf("unknown txType %s for chain %v", c.TxType, *c.Id)
+	}
+	switch c.GasPricer {
+	case "", NodeGasPricer, EthGasStationGasPricer:
+	case StaticGasPricer:
+		if c.GasPrice <= 0 {
+			return fmt.Errorf("required field chain.GasPrice empty for static gas pricer of chain %v", *c.Id)
+		}
+	case PercentileGasPricer:
+		if c.GasPricePercentile < 0 || c.GasPricePercentile > 100 {
+			return fmt.Errorf("gasPricePercentile %d of chain %v is not in range 0-100, 0 uses default percentile", c.GasPricePercentile, *c.Id)
+		}
+	default:
+		return fmt.Errorf("unknown gasPricer %s for chain %v", c.GasPricer, *c.Id)
+	}
+	if c.MinGasPrice != 0 && c.MaxGasPrice != 0 && c.MinGasPrice > c.MaxGasPrice {
+		return fmt.Errorf("minGasPrice is greater than maxGasPrice for chain %v", *c.Id)
+	}
+	if c.BlockConfirmations != nil && *c.BlockConfirmations < 0 {
+		return fmt.Errorf("blockConfirmations can not be negative for chain %v", *c.Id)
+	}
 	return nil
 }
 
@@ -52,11 +105,17 @@ func (c *RawSharedEVMConfig) ParseConfig() (*SharedEVMConfig, error) {
 		Erc20Handler:       c.Erc20Handler,
 		Erc721Handler:      c.Erc721Handler,
 		GenericHandler:     c.GenericHandler,
+		TxType:             c.TxType,
+		GasPricer:          NodeGasPricer,
+		GasPricePercentile: consts.DefaultGasPricePercentile,
 		GasLimit:           big.NewInt(consts.DefaultGasLimit),
 		MaxGasPrice:        big.NewInt(consts.DefaultGasPrice),
 		GasMultiplier:      big.NewFloat(consts.DefaultGasMultiplier),
 		StartBlock:         big.NewInt(c.StartBlock),
 		BlockConfirmations: big.NewInt(consts.DefaultBlockConfirmations),
+		BlockRange:         big.NewInt(consts.DefaultBlockRange),
+		BlockRetryInterval: time.Duration(consts.DefaultBlockRetryInterval) * time.Second,
+		ProposalTimeout:    big.NewInt(consts.DefaultProposalTimeout),
 	}
 
 	if c.Bridge != "" {
@@ -69,6 +128,22 @@ func (c *RawSharedEVMConfig) ParseConfig() (*SharedEVMConfig, error) {
 		config.GasLimit = big.NewInt(c.GasLimit)
 	}
 
+	if c.GasPricer != "" {
+		config.GasPricer = c.GasPricer
+	}
+
+	if c.GasPrice != 0 {
+		config.GasPrice = big.NewInt(c.GasPrice)
+	}
+
+	if c.GasPricePercentile != 0 {
+		config.GasPricePercentile = c.GasPricePercentile
+	}
+
+	if c.MinGasPrice != 0 {
+		config.MinGasPrice = big.NewInt(c.MinGasPrice)
+	}
+
 	if c.MaxGasPrice != 0 {
 		config.MaxGasPrice = big.NewInt(c.MaxGasPrice)
 	}
@@ -77,8 +152,20 @@ func (c *RawSharedEVMConfig) ParseConfig() (*SharedEVMConfig, error) {
 		config.GasMultiplier = big.NewFloat(c.GasMultiplier)
 	}
 
-	if c.BlockConfirmations != 0 {
-		config.BlockConfirmations = big.NewInt(c.BlockConfirmations)
+	if c.BlockConfirmations != nil {
+		config.BlockConfirmations = big.NewInt(*c.BlockConfirmations)
+	}
+
+	if c.BlockRange != 0 {
+		config.BlockRange = big.NewInt(c.BlockRange)
+	}
+
+	if c.BlockRetryInterval != 0 {
+		config.BlockRetryInterval = time.Duration(c.BlockRetryInterval) * time.Second
+	}
+
+	if c.ProposalTimeout != 0 {
+		config.ProposalTimeout = big.NewInt(c.ProposalTimeout)
 	}
 
 	return config, nil
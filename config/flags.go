@@ -1,22 +1,29 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	// Flags for running the Chainbridge app
-	ConfigFlagName      = "chain_config"
-	KeystoreFlagName    = "keystore"
-	BlockstoreFlagName  = "blockstore"
-	FreshStartFlagName  = "fresh"
-	LatestBlockFlagName = "latest"
-	TestKeyFlagName     = "testkey"
+	ConfigFlagName          = "chain_config"
+	KeystoreFlagName        = "keystore"
+	BlockstoreFlagName      = "blockstore"
+	FreshStartFlagName      = "fresh"
+	LatestBlockFlagName     = "latest"
+	TestKeyFlagName         = "testkey"
+	MetricsFlagName         = "metrics"
+	MetricsAddressFlagName  = "metrics_address"
+	HealthFlagName          = "health"
+	StallThresholdFlagName  = "stall_threshold"
+	ShutdownTimeoutFlagName = "shutdown_timeout"
 )
 
 func BindFlags(rootCMD *cobra.Command) {
-	rootCMD.PersistentFlags().String(ConfigFlagName, ".", "Path to JSON configuration files directory")
+	rootCMD.PersistentFlags().String(ConfigFlagName, ".", "Path to JSON configuration file or directory of per chain JSON configuration files")
 	_ = viper.BindPFlag(ConfigFlagName, rootCMD.PersistentFlags().Lookup(ConfigFlagName))
 
 	rootCMD.PersistentFlags().String(BlockstoreFlagName, "./lvldbdata", "Specify path for blockstore")
@@ -33,4 +40,19 @@ func BindFlags(rootCMD *cobra.Command) {
 
 	rootCMD.PersistentFlags().String(TestKeyFlagName, "", "Applies a predetermined test keystore to the chains.")
 	_ = viper.BindPFlag(TestKeyFlagName, rootCMD.PersistentFlags().Lookup(TestKeyFlagName))
+
+	rootCMD.PersistentFlags().Bool(MetricsFlagName, true, "Enables serving of prometheus metrics")
+	_ = viper.BindPFlag(MetricsFlagName, rootCMD.PersistentFlags().Lookup(MetricsFlagName))
+
+	rootCMD.PersistentFlags().String(MetricsAddressFlagName, ":2112", "Address metrics and health endpoints are served on")
+	_ = viper.BindPFlag(MetricsAddressFlagName, rootCMD.PersistentFlags().Lookup(MetricsAddressFlagName))
+
+	rootCMD.PersistentFlags().Bool(HealthFlagName, true, "Enables serving of /health and /ready endpoints")
+	_ = viper.BindPFlag(HealthFlagName, rootCMD.PersistentFlags().Lookup(HealthFlagName))
+
+	rootCMD.PersistentFlags().Duration(StallThresholdFlagName, time.Minute*5, "Time without processed blocks after which chain is reported as stalled")
+	_ = viper.BindPFlag(StallThresholdFlagName, rootCMD.PersistentFlags().Lookup(StallThresholdFlagName))
+
+	rootCMD.PersistentFlags().Duration(ShutdownTimeoutFlagName, time.Minute, "Time to wait for in-flight proposals on shutdown")
+	_ = viper.BindPFlag(ShutdownTimeoutFlagName, rootCMD.PersistentFlags().Lookup(ShutdownTimeoutFlagName))
 }
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"math/big"
+
+	"github.com/mitchellh/mapstructure"
 )
 
 type SharedSubstrateConfig struct {
@@ -16,6 +19,24 @@ type RawSharedSubstrateConfig struct {
 	UseExtendedCall    bool  `mapstructure:"useExtendedCall"`
 }
 
+func (c *RawSharedSubstrateConfig) Validate() error {
+	return c.GeneralChainConfig.Validate()
+}
+
+// NewSubstrateConfig decodes, validates and parses a single chain entry of the relayer config
+func NewSubstrateConfig(chainConfig map[string]interface{}) (*SharedSubstrateConfig, error) {
+	rawConfig := &RawSharedSubstrateConfig{}
+	if err := mapstructure.Decode(chainConfig, rawConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode substrate chain config, error: %w", err)
+	}
+
+	if err := rawConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	return rawConfig.ParseConfig(), nil
+}
+
 func (c *RawSharedSubstrateConfig) ParseConfig() *SharedSubstrateConfig {
 
 	c.GeneralChainConfig.ParseConfig()
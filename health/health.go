@@ -0,0 +1,121 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CheckTimeout limits how long status of a single chain is collected
+var CheckTimeout = time.Second * 10
+
+// ChainStatus is the state of a single relayed chain reported by health endpoints
+type ChainStatus struct {
+	ChainID      uint8 `json:"chainId"`
+	RPCReachable bool  `json:"rpcReachable"`
+	// LastProcessedBlock is nil until listener processes its first block range
+	LastProcessedBlock *big.Int `json:"lastProcessedBlock"`
+	// LastProcessedAt is time of the last processed block range or listener start if nothing was processed yet
+	LastProcessedAt         time.Time `json:"lastProcessedAt"`
+	LastProcessedAgeSeconds float64   `json:"lastProcessedAgeSeconds"`
+	BlocksBehindHead        *big.Int  `json:"blocksBehindHead"`
+	KeystoreLoaded          bool      `json:"keystoreLoaded"`
+	IsRelayer               bool      `json:"isRelayer"`
+	Stalled                 bool      `json:"stalled"`
+	Errors                  []string  `json:"errors,omitempty"`
+}
+
+// Ready reports if chain is able to relay messages
+func (s *ChainStatus) Ready() bool {
+	return s.RPCReachable && s.KeystoreLoaded && s.IsRelayer && s.LastProcessedBlock != nil && !s.Stalled
+}
+
+// Chain is a relayed chain able to report its status
+type Chain interface {
+	ChainID() uint8
+	Status(ctx context.Context) ChainStatus
+}
+
+// Checker collects status of relayed chains. Chain is considered stalled if it did not process
+// any block range for longer than stallThreshold.
+type Checker struct {
+	chains         []Chain
+	stallThreshold time.Duration
+}
+
+func NewChecker(chains []Chain, stallThreshold time.Duration) *Checker {
+	return &Checker{chains: chains, stallThreshold: stallThreshold}
+}
+
+// Check returns status of every chain
+func (c *Checker) Check(ctx context.Context) []ChainStatus {
+	statuses := make([]ChainStatus, len(c.chains))
+	for i, chain := range c.chains {
+		chainCtx, cancel := context.WithTimeout(ctx, CheckTimeout)
+		status := chain.Status(chainCtx)
+		cancel()
+		status.ChainID = chain.ChainID()
+		if !status.LastProcessedAt.IsZero() {
+			age := time.Since(status.LastProcessedAt)
+			status.LastProcessedAgeSeconds = age.Seconds()
+			status.Stalled = age > c.stallThreshold
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
+
+type response struct {
+	Status string        `json:"status"`
+	Chains []ChainStatus `json:"chains"`
+}
+
+// HealthHandler responds with status of every chain and 503 code if any chain is stalled
+func (c *Checker) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := c.Check(r.Context())
+		healthy := true
+		for i := range statuses {
+			if statuses[i].Stalled {
+				healthy = false
+			}
+		}
+		writeResponse(w, healthy, statuses)
+	})
+}
+
+// ReadyHandler responds with status of every chain and 503 code if any chain is not ready to relay messages
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := c.Check(r.Context())
+		ready := true
+		for i := range statuses {
+			if !statuses[i].Ready() {
+				ready = false
+			}
+		}
+		writeResponse(w, ready, statuses)
+	})
+}
+
+func writeResponse(w http.ResponseWriter, ok bool, statuses []ChainStatus) {
+	res := response{Status: "ok", Chains: statuses}
+	code := http.StatusOK
+	if !ok {
+		res.Status = "unavailable"
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(res)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to write health response")
+	}
+}
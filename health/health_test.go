@@ -0,0 +1,116 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockChain struct {
+	id     uint8
+	status ChainStatus
+}
+
+func (c *mockChain) ChainID() uint8 {
+	return c.id
+}
+
+func (c *mockChain) Status(ctx context.Context) ChainStatus {
+	return c.status
+}
+
+func readyStatus(processedAt time.Time) ChainStatus {
+	return ChainStatus{
+		RPCReachable:       true,
+		LastProcessedBlock: big.NewInt(100),
+		LastProcessedAt:    processedAt,
+		BlocksBehindHead:   big.NewInt(2),
+		KeystoreLoaded:     true,
+		IsRelayer:          true,
+	}
+}
+
+func serve(t *testing.T, handler http.Handler) (int, response) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	res := response{}
+	err := json.Unmarshal(rec.Body.Bytes(), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, res
+}
+
+func TestHealthyChains(t *testing.T) {
+	checker := NewChecker([]Chain{
+		&mockChain{id: 1, status: readyStatus(time.Now())},
+		&mockChain{id: 2, status: readyStatus(time.Now().Add(-time.Minute))},
+	}, time.Minute*5)
+
+	code, res := serve(t, checker.HealthHandler())
+	if code != http.StatusOK {
+		t.Fatalf("expected health code %d, got %d", http.StatusOK, code)
+	}
+	if len(res.Chains) != 2 || res.Chains[0].ChainID != 1 || res.Chains[1].ChainID != 2 {
+		t.Fatalf("unexpected chains %+v", res.Chains)
+	}
+	if res.Chains[1].LastProcessedAgeSeconds < 60 {
+		t.Fatalf("expected age of at least 60 seconds, got %f", res.Chains[1].LastProcessedAgeSeconds)
+	}
+
+	code, _ = serve(t, checker.ReadyHandler())
+	if code != http.StatusOK {
+		t.Fatalf("expected ready code %d, got %d", http.StatusOK, code)
+	}
+}
+
+func TestStalledChain(t *testing.T) {
+	checker := NewChecker([]Chain{
+		&mockChain{id: 1, status: readyStatus(time.Now())},
+		&mockChain{id: 2, status: readyStatus(time.Now().Add(-time.Minute * 10))},
+	}, time.Minute*5)
+
+	code, res := serve(t, checker.HealthHandler())
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected health code %d, got %d", http.StatusServiceUnavailable, code)
+	}
+	if res.Chains[0].Stalled || !res.Chains[1].Stalled {
+		t.Fatalf("expected only chain 2 to be stalled %+v", res.Chains)
+	}
+
+	code, _ = serve(t, checker.ReadyHandler())
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected ready code %d, got %d", http.StatusServiceUnavailable, code)
+	}
+}
+
+func TestNotReadyChain(t *testing.T) {
+	notRelayer := readyStatus(time.Now())
+	notRelayer.IsRelayer = false
+	unreachable := readyStatus(time.Now())
+	unreachable.RPCReachable = false
+	notStarted := readyStatus(time.Now())
+	notStarted.LastProcessedBlock = nil
+
+	for name, status := range map[string]ChainStatus{"not relayer": notRelayer, "unreachable": unreachable, "not started": notStarted} {
+		t.Run(name, func(t *testing.T) {
+			checker := NewChecker([]Chain{&mockChain{id: 1, status: status}}, time.Minute*5)
+
+			code, _ := serve(t, checker.HealthHandler())
+			if code != http.StatusOK {
+				t.Fatalf("expected health code %d, got %d", http.StatusOK, code)
+			}
+			code, _ = serve(t, checker.ReadyHandler())
+			if code != http.StatusServiceUnavailable {
+				t.Fatalf("expected ready code %d, got %d", http.StatusServiceUnavailable, code)
+			}
+		})
+	}
+}
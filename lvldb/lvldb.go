@@ -6,6 +6,7 @@ package lvldb
 import (
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 type LVLDB struct {
@@ -28,6 +29,26 @@ func (db *LVLDB) SetByKey(key []byte, value []byte) error {
 	return db.db.Put(key, value, nil)
 }
 
+func (db *LVLDB) DeleteByKey(key []byte) error {
+	return db.db.Delete(key, nil)
+}
+
+// IterateByPrefix calls f for every key-value pair with the provided key prefix in key order.
+// Iteration stops on the first error returned by f.
+func (db *LVLDB) IterateByPrefix(prefix []byte, f func(key []byte, value []byte) error) error {
+	iter := db.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+	for iter.Next() {
+		// iterator reuses key and value buffers between iterations
+		key := append([]byte{}, iter.Key()...)
+		value := append([]byte{}, iter.Value()...)
+		if err := f(key, value); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
 func (db *LVLDB) Close() error {
 	return db.db.Close()
 }
@@ -0,0 +1,179 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package messagestore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/StirNetwork/chainbridge-core/relayer"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const messagePrefix = "message:"
+
+var ErrMessageNotQuarantined = errors.New("message is not quarantined")
+
+type KeyValueReaderWriter interface {
+	GetByKey(key []byte) ([]byte, error)
+	SetByKey(key []byte, value []byte) error
+	IterateByPrefix(prefix []byte, f func(key []byte, value []byte) error) error
+}
+
+type storedMessage struct {
+	Status  relayer.MessageStatus
+	Message *relayer.Message
+	// Reason why message was skipped or quarantined
+	Reason string
+}
+
+// MessageRecord is a stored message with its status and the reason processor skipped or quarantined it
+type MessageRecord struct {
+	Message *relayer.Message
+	Status  relayer.MessageStatus
+	Reason  string
+}
+
+// MessageStore persists relayer messages and their delivery status.
+// Messages are keyed by source, destination and deposit nonce.
+type MessageStore struct {
+	db KeyValueReaderWriter
+}
+
+func NewMessageStore(db KeyValueReaderWriter) *MessageStore {
+	return &MessageStore{db: db}
+}
+
+// StoreMessage writes message with provided status, overwriting any previous record of it
+func (s *MessageStore) StoreMessage(m *relayer.Message, status relayer.MessageStatus) error {
+	return s.store(&storedMessage{Status: status, Message: m})
+}
+
+// StoreMessageWithReason writes message with provided status and the reason processor skipped or quarantined it,
+// overwriting any previous record of it
+func (s *MessageStore) StoreMessageWithReason(m *relayer.Message, status relayer.MessageStatus, reason string) error {
+	return s.store(&storedMessage{Status: status, Message: m, Reason: reason})
+}
+
+func (s *MessageStore) store(sm *storedMessage) error {
+	value := bytes.Buffer{}
+	err := gob.NewEncoder(&value).Encode(sm)
+	if err != nil {
+		return err
+	}
+	return s.db.SetByKey(messageKey(sm.Message), value.Bytes())
+}
+
+// GetMessageStatus returns status of stored message. Returns relayer.ErrMessageNotFound if message was never stored.
+func (s *MessageStore) GetMessageStatus(m *relayer.Message) (relayer.MessageStatus, error) {
+	_, status, err := s.GetMessage(m)
+	return status, err
+}
+
+// GetMessageReason returns the reason processor skipped or quarantined stored message.
+// Returns relayer.ErrMessageNotFound if message was never stored.
+func (s *MessageStore) GetMessageReason(m *relayer.Message) (string, error) {
+	sm, err := s.get(messageKey(m))
+	if err != nil {
+		return "", err
+	}
+	return sm.Reason, nil
+}
+
+// GetMessage returns stored message with the same source, destination and deposit nonce as m, and its status.
+// Returns relayer.ErrMessageNotFound if message was never stored.
+func (s *MessageStore) GetMessage(m *relayer.Message) (*relayer.Message, relayer.MessageStatus, error) {
+	sm, err := s.get(messageKey(m))
+	if err != nil {
+		return nil, relayer.MessageStatusPending, err
+	}
+	return sm.Message, sm.Status, nil
+}
+
+func (s *MessageStore) get(key []byte) (*storedMessage, error) {
+	v, err := s.db.GetByKey(key)
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return nil, relayer.ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return decodeMessage(v)
+}
+
+// PendingMessages returns all messages that are not yet written to destination, including approved quarantined messages,
+// ordered by deposit nonce
+func (s *MessageStore) PendingMessages() ([]*relayer.Message, error) {
+	msgs := make([]*relayer.Message, 0)
+	err := s.iterate(func(sm *storedMessage) {
+		if sm.Status == relayer.MessageStatusPending || sm.Status == relayer.MessageStatusApproved {
+			msgs = append(msgs, sm.Message)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgs[i].DepositNonce < msgs[j].DepositNonce
+	})
+	return msgs, nil
+}
+
+// MessagesByStatus returns all messages with status, ordered by deposit nonce
+func (s *MessageStore) MessagesByStatus(status relayer.MessageStatus) ([]*MessageRecord, error) {
+	msgs := make([]*MessageRecord, 0)
+	err := s.iterate(func(sm *storedMessage) {
+		if sm.Status == status {
+			msgs = append(msgs, &MessageRecord{Message: sm.Message, Status: sm.Status, Reason: sm.Reason})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgs[i].Message.DepositNonce < msgs[j].Message.DepositNonce
+	})
+	return msgs, nil
+}
+
+// ApproveMessage approves quarantined message identified by source, destination and deposit nonce.
+// Approved message is written to destination on the next relayer start.
+func (s *MessageStore) ApproveMessage(source, destination uint8, nonce uint64) error {
+	sm, err := s.get(messageKey(&relayer.Message{Source: source, Destination: destination, DepositNonce: nonce}))
+	if err != nil {
+		return err
+	}
+	if sm.Status != relayer.MessageStatusQuarantined {
+		return fmt.Errorf("%w, message status is %s", ErrMessageNotQuarantined, sm.Status)
+	}
+	sm.Status = relayer.MessageStatusApproved
+	return s.store(sm)
+}
+
+func (s *MessageStore) iterate(f func(sm *storedMessage)) error {
+	return s.db.IterateByPrefix([]byte(messagePrefix), func(key []byte, value []byte) error {
+		sm, err := decodeMessage(value)
+		if err != nil {
+			return fmt.Errorf("error %w decoding message %s", err, string(key))
+		}
+		f(sm)
+		return nil
+	})
+}
+
+func decodeMessage(value []byte) (*storedMessage, error) {
+	sm := &storedMessage{}
+	err := gob.NewDecoder(bytes.NewReader(value)).Decode(sm)
+	if err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+func messageKey(m *relayer.Message) []byte {
+	return []byte(fmt.Sprintf("%s%d:%d:%d", messagePrefix, m.Source, m.Destination, m.DepositNonce))
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package messagestore
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/StirNetwork/chainbridge-core/lvldb"
+	"github.com/StirNetwork/chainbridge-core/relayer"
+)
+
+func newTestStore(t *testing.T) (*MessageStore, func()) {
+	dir, err := ioutil.TempDir("", "messagestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := lvldb.NewLvlDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewMessageStore(db), func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func testMessage(nonce uint64) *relayer.Message {
+	return &relayer.Message{
+		Source:       1,
+		Destination:  2,
+		DepositNonce: nonce,
+		ResourceId:   [32]byte{1},
+		Type:         relayer.FungibleTransfer,
+		Payload: &relayer.FungibleTransferPayload{
+			Amount:    big.NewInt(100),
+			Recipient: []byte{0xab, 0xcd},
+		},
+	}
+}
+
+func TestMessageStore_StatusLifecycle(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	m := testMessage(1)
+	_, err := s.GetMessageStatus(m)
+	if !errors.Is(err, relayer.ErrMessageNotFound) {
+		t.Fatalf("expected ErrMessageNotFound, got %v", err)
+	}
+
+	err = s.StoreMessage(m, relayer.MessageStatusPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := s.GetMessageStatus(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != relayer.MessageStatusPending {
+		t.Fatalf("expected pending status, got %v", status)
+	}
+
+	err = s.StoreMessage(m, relayer.MessageStatusDone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err = s.GetMessageStatus(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != relayer.MessageStatusDone {
+		t.Fatalf("expected done status, got %v", status)
+	}
+
+	stored, status, err := s.GetMessage(&relayer.Message{Source: 1, Destination: 2, DepositNonce: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored, m) || status != relayer.MessageStatusDone {
+		t.Fatalf("unexpected stored message %+v with status %v", stored, status)
+	}
+}
+
+func TestMessageStore_PendingMessages(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	for _, nonce := range []uint64{12, 3, 7} {
+		if err := s.StoreMessage(testMessage(nonce), relayer.MessageStatusPending); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.StoreMessage(testMessage(7), relayer.MessageStatusDone); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := s.PendingMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending messages, got %d", len(pending))
+	}
+	if pending[0].DepositNonce != 3 || pending[1].DepositNonce != 12 {
+		t.Fatalf("pending messages not ordered by nonce: %d, %d", pending[0].DepositNonce, pending[1].DepositNonce)
+	}
+	if !reflect.DeepEqual(pending[0], testMessage(3)) {
+		t.Fatalf("decoded message does not match\ngot: %+v\nexpected: %+v", pending[0], testMessage(3))
+	}
+}
+
+func TestMessageStore_MessagesByStatus(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	for _, nonce := range []uint64{5, 2} {
+		if err := s.StoreMessage(testMessage(nonce), relayer.MessageStatusPending); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.StoreMessageWithReason(testMessage(nonce), relayer.MessageStatusSkipped, "denied"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.StoreMessage(testMessage(3), relayer.MessageStatusPending); err != nil {
+		t.Fatal(err)
+	}
+
+	skipped, err := s.MessagesByStatus(relayer.MessageStatusSkipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 2 || skipped[0].Message.DepositNonce != 2 || skipped[1].Message.DepositNonce != 5 {
+		t.Fatalf("unexpected skipped messages %+v", skipped)
+	}
+	if skipped[0].Reason != "denied" || skipped[0].Status != relayer.MessageStatusSkipped || !reflect.DeepEqual(skipped[0].Message, testMessage(2)) {
+		t.Fatalf("unexpected skipped message %+v", skipped[0])
+	}
+	status, err := s.GetMessageStatus(testMessage(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != relayer.MessageStatusSkipped {
+		t.Fatalf("expected skipped status, got %v", status)
+	}
+	pending, err := s.PendingMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending message, got %d", len(pending))
+	}
+}
+
+func TestMessageStore_ApproveMessage(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	err := s.StoreMessageWithReason(testMessage(4), relayer.MessageStatusQuarantined, "over limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := s.PendingMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("quarantined message must not be pending, got %d pending messages", len(pending))
+	}
+
+	err = s.ApproveMessage(1, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err = s.PendingMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || !reflect.DeepEqual(pending[0], testMessage(4)) {
+		t.Fatalf("approved message must be pending, got %+v", pending)
+	}
+	reason, err := s.GetMessageReason(testMessage(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason != "over limit" {
+		t.Fatalf("expected quarantine reason to be kept after approval, got %q", reason)
+	}
+
+	if err := s.ApproveMessage(1, 2, 4); !errors.Is(err, ErrMessageNotQuarantined) {
+		t.Fatalf("expected ErrMessageNotQuarantined, got %v", err)
+	}
+	if err := s.ApproveMessage(1, 2, 5); !errors.Is(err, relayer.ErrMessageNotFound) {
+		t.Fatalf("expected ErrMessageNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package metrics
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// AmountCounter accumulates token amounts per label value as exact big integers in token base units. Totals are
+// converted to float64 only when collected.
+type AmountCounter struct {
+	desc   *prometheus.Desc
+	lock   sync.Mutex
+	totals map[string]*big.Int
+}
+
+func NewAmountCounter(opts prometheus.Opts, label string) *AmountCounter {
+	return &AmountCounter{
+		desc:   prometheus.NewDesc(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), opts.Help, []string{label}, opts.ConstLabels),
+		totals: make(map[string]*big.Int),
+	}
+}
+
+// Add adds amount to total of labelValue
+func (c *AmountCounter) Add(labelValue string, amount *big.Int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	total, ok := c.totals[labelValue]
+	if !ok {
+		total = big.NewInt(0)
+		c.totals[labelValue] = total
+	}
+	total.Add(total, amount)
+}
+
+// Total returns exact total of labelValue
+func (c *AmountCounter) Total(labelValue string) *big.Int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	total, ok := c.totals[labelValue]
+	if !ok {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(total)
+}
+
+func (c *AmountCounter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *AmountCounter) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for labelValue, total := range c.totals {
+		f, _ := new(big.Float).SetInt(total).Float64()
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, f, labelValue)
+	}
+}
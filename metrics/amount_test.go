@@ -0,0 +1,37 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package metrics
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestAmountCounterCollectsTotals(t *testing.T) {
+	c := NewAmountCounter(prometheus.Opts{Name: "amount", Help: "Amount"}, "resource_id")
+	amount, _ := new(big.Int).SetString("1500000000000000000", 10)
+	c.Add("erc20", amount)
+	c.Add("erc20", amount)
+	c.Add("erc20", big.NewInt(1))
+	c.Add("erc721", big.NewInt(7))
+
+	// Total stays exact, it loses precision only when collected
+	if total := c.Total("erc20"); total.String() != "3000000000000000001" {
+		t.Fatalf("unexpected total %s", total)
+	}
+	expected := `
+# HELP amount Amount
+# TYPE amount counter
+amount{resource_id="erc20"} 3e+18
+amount{resource_id="erc721"} 7
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,36 +4,224 @@
 package metrics
 
 import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // ChainMetrics is a public struct that includes data related to transfers occuring over the chainbridge
 type ChainMetrics struct {
-	// Total amount of tokens that have been transferred
-	AmountTransferred prometheus.Counter
-	// Total number of transfers that have occurred
-	NumberOfTransfers prometheus.Counter
+	// Total amount of tokens that have been transferred per resource ID
+	AmountTransferred *AmountCounter
+	// Total number of transfers that have occurred per transfer type
+	NumberOfTransfers *prometheus.CounterVec
 }
 
-// NewChainMetrics is a public function to initialise a new instance of ChainMetrics
+// NewChainMetrics is a public function to initialise a new instance of ChainMetrics. Already registered collectors are reused
 func NewChainMetrics() *ChainMetrics {
-	metrics := &ChainMetrics{
-		AmountTransferred: prometheus.NewCounter(prometheus.CounterOpts{
+	return &ChainMetrics{
+		AmountTransferred: register(NewAmountCounter(prometheus.Opts{
 			Namespace: "chainbridge",
 			Name:      "total_amount_transferred",
 			Subsystem: "analytics",
 			Help:      "Number of tokens transferred across bridge",
-		}),
-		NumberOfTransfers: prometheus.NewCounter(prometheus.CounterOpts{
+		}, "resource_id")).(*AmountCounter),
+		NumberOfTransfers: registerCounterVec(prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "chainbridge",
 			Name:      "total_number_of_transfers",
 			Subsystem: "analytics",
 			Help:      "Number of transfers occurred across bridge",
-		}),
+		}, []string{"type"})),
+	}
+}
+
+// ReorgMetrics tracks chain reorganisations detected by chain listeners
+type ReorgMetrics struct {
+	// Number of detected reorgs of already processed blocks per chain
+	Reorgs *prometheus.CounterVec
+	// Number of messages relayed from blocks that were orphaned by reorg per chain
+	OrphanedMessages *prometheus.CounterVec
+}
+
+// NewReorgMetrics initialises ReorgMetrics. It is safe to call for every chain, already registered collectors are reused
+func NewReorgMetrics() *ReorgMetrics {
+	return &ReorgMetrics{
+		Reorgs: registerCounterVec(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chainbridge",
+			Name:      "total_number_of_reorgs",
+			Subsystem: "listener",
+			Help:      "Number of reorgs of already processed blocks",
+		}, []string{"chain"})),
+		OrphanedMessages: registerCounterVec(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chainbridge",
+			Name:      "total_number_of_orphaned_messages",
+			Subsystem: "listener",
+			Help:      "Number of relayed messages from blocks orphaned by reorg",
+		}, []string{"chain"})),
 	}
+}
+
+// BridgeMetrics tracks deposits and proposals per source chain, destination chain and resource ID
+type BridgeMetrics struct {
+	// Number of deposits read from source chains
+	DepositsSeen *prometheus.CounterVec
+	// Number of proposals voted by relayer
+	ProposalsVoted *prometheus.CounterVec
+	// Number of proposals executed on destination chain
+	ProposalsExecuted *prometheus.CounterVec
+	// Number of failures of relaying deposits by reason
+	Failures *prometheus.CounterVec
+	// Time between relayer vote and proposal execution
+	VoteToExecutionLatency *prometheus.HistogramVec
+	// Number of messages waiting for a worker per destination chain
+	QueueDepth *prometheus.GaugeVec
+}
+
+var bridgeLabels = []string{"source", "destination", "resource_id"}
+
+// NewBridgeMetrics initialises BridgeMetrics. It is safe to call for every chain, already registered collectors are reused
+func NewBridgeMetrics() *BridgeMetrics {
+	return &BridgeMetrics{
+		DepositsSeen: registerCounterVec(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chainbridge",
+			Name:      "total_number_of_deposits",
+			Subsystem: "relayer",
+			Help:      "Number of deposits read from source chains",
+		}, bridgeLabels)),
+		ProposalsVoted: registerCounterVec(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chainbridge",
+			Name:      "total_number_of_voted_proposals",
+			Subsystem: "relayer",
+			Help:      "Number of proposals voted by relayer",
+		}, bridgeLabels)),
+		ProposalsExecuted: registerCounterVec(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chainbridge",
+			Name:      "total_number_of_executed_proposals",
+			Subsystem: "relayer",
+			Help:      "Number of proposals executed on destination chain",
+		}, bridgeLabels)),
+		Failures: registerCounterVec(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chainbridge",
+			Name:      "total_number_of_failures",
+			Subsystem: "relayer",
+			Help:      "Number of failures of relaying deposits by reason",
+		}, append(bridgeLabels, "reason"))),
+		VoteToExecutionLatency: registerHistogramVec(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "chainbridge",
+			Name:      "vote_to_execution_seconds",
+			Subsystem: "relayer",
+			Help:      "Time between relayer vote and proposal execution",
+			Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 1800},
+		}, bridgeLabels)),
+		QueueDepth: registerGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "chainbridge",
+			Name:      "queue_depth",
+			Subsystem: "relayer",
+			Help:      "Number of messages waiting for a worker per destination chain",
+		}, []string{"destination"})),
+	}
+}
+
+func bridgeLabelValues(source, destination uint8, resourceID [32]byte) []string {
+	return []string{strconv.Itoa(int(source)), strconv.Itoa(int(destination)), fmt.Sprintf("%x", resourceID)}
+}
+
+func (m *BridgeMetrics) DepositSeen(source, destination uint8, resourceID [32]byte) {
+	m.DepositsSeen.WithLabelValues(bridgeLabelValues(source, destination, resourceID)...).Inc()
+}
+
+func (m *BridgeMetrics) ProposalVoted(source, destination uint8, resourceID [32]byte) {
+	m.ProposalsVoted.WithLabelValues(bridgeLabelValues(source, destination, resourceID)...).Inc()
+}
 
-	prometheus.MustRegister(metrics.AmountTransferred)
-	prometheus.MustRegister(metrics.NumberOfTransfers)
+func (m *BridgeMetrics) ProposalExecuted(source, destination uint8, resourceID [32]byte) {
+	m.ProposalsExecuted.WithLabelValues(bridgeLabelValues(source, destination, resourceID)...).Inc()
+}
+
+func (m *BridgeMetrics) Failure(source, destination uint8, resourceID [32]byte, reason string) {
+	m.Failures.WithLabelValues(append(bridgeLabelValues(source, destination, resourceID), reason)...).Inc()
+}
+
+func (m *BridgeMetrics) ObserveVoteToExecution(source, destination uint8, resourceID [32]byte, latency time.Duration) {
+	m.VoteToExecutionLatency.WithLabelValues(bridgeLabelValues(source, destination, resourceID)...).Observe(latency.Seconds())
+}
+
+// DestinationQueueDepth returns queue depth gauge of destination chain
+func (m *BridgeMetrics) DestinationQueueDepth(destination uint8) prometheus.Gauge {
+	return m.QueueDepth.WithLabelValues(strconv.Itoa(int(destination)))
+}
+
+// ChainStateMetrics tracks listener progress and relayer account of every chain
+type ChainStateMetrics struct {
+	// Last block processed by chain listener
+	LatestProcessedBlock *prometheus.GaugeVec
+	// Number of blocks between chain head and last processed block
+	HeadLag *prometheus.GaugeVec
+	// Balance of relayer account in the smallest chain currency unit
+	RelayerBalance *prometheus.GaugeVec
+}
+
+// NewChainStateMetrics initialises ChainStateMetrics. It is safe to call for every chain, already registered collectors are reused
+func NewChainStateMetrics() *ChainStateMetrics {
+	return &ChainStateMetrics{
+		LatestProcessedBlock: registerGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "chainbridge",
+			Name:      "latest_processed_block",
+			Subsystem: "listener",
+			Help:      "Last block processed by chain listener",
+		}, []string{"chain"})),
+		HeadLag: registerGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "chainbridge",
+			Name:      "head_lag_blocks",
+			Subsystem: "listener",
+			Help:      "Number of blocks between chain head and last processed block",
+		}, []string{"chain"})),
+		RelayerBalance: registerGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "chainbridge",
+			Name:      "relayer_balance",
+			Subsystem: "chain",
+			Help:      "Balance of relayer account in the smallest chain currency unit",
+		}, []string{"chain"})),
+	}
+}
+
+// ProcessedBlock records last processed block of chain and its distance from head
+func (m *ChainStateMetrics) ProcessedBlock(chainID uint8, block *big.Int, head *big.Int) {
+	chain := strconv.Itoa(int(chainID))
+	b, _ := new(big.Float).SetInt(block).Float64()
+	m.LatestProcessedBlock.WithLabelValues(chain).Set(b)
+	lag, _ := new(big.Float).SetInt(new(big.Int).Sub(head, block)).Float64()
+	m.HeadLag.WithLabelValues(chain).Set(lag)
+}
+
+func (m *ChainStateMetrics) Balance(chainID uint8, balance *big.Int) {
+	b, _ := new(big.Float).SetInt(balance).Float64()
+	m.RelayerBalance.WithLabelValues(strconv.Itoa(int(chainID))).Set(b)
+}
+
+// register registers collector. If equal collector is already registered, for example by other chain, it is returned instead
+func register(c prometheus.Collector) prometheus.Collector {
+	err := prometheus.Register(c)
+	if err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+func registerCounterVec(c *prometheus.CounterVec) *prometheus.CounterVec {
+	return register(c).(*prometheus.CounterVec)
+}
+
+func registerGaugeVec(c *prometheus.GaugeVec) *prometheus.GaugeVec {
+	return register(c).(*prometheus.GaugeVec)
+}
 
-	return metrics
+func registerHistogramVec(c *prometheus.HistogramVec) *prometheus.HistogramVec {
+	return register(c).(*prometheus.HistogramVec)
 }
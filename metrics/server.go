@@ -0,0 +1,58 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+)
+
+// Server serves relayer HTTP endpoints such as prometheus metrics and health checks
+type Server struct {
+	addr   string
+	router *mux.Router
+	server *http.Server
+}
+
+func NewServer(addr string) *Server {
+	router := mux.NewRouter()
+	return &Server{addr: addr, router: router, server: &http.Server{Handler: router}}
+}
+
+// Handle registers handler for path, must be called before Start
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.router.Path(path).Handler(handler)
+}
+
+// HandleMetrics serves prometheus metrics on /metrics
+func (s *Server) HandleMetrics() {
+	s.Handle("/metrics", promhttp.Handler())
+}
+
+// Start binds server address and serves requests in background. Returns error if address can not be bound
+func (s *Server) Start() error {
+	l, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Serving HTTP endpoints on %s", l.Addr().String())
+	go func() {
+		err := s.server.Serve(l)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("HTTP server stopped")
+		}
+	}()
+	return nil
+}
+
+// Stop gracefully shuts down the server
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
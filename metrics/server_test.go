@@ -0,0 +1,35 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestServerStartFailsOnTakenAddress(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	err = NewServer(l.Addr().String()).Start()
+	if err == nil {
+		t.Fatal("expected error for address in use")
+	}
+}
+
+func TestServerStartAndStop(t *testing.T) {
+	s := NewServer("127.0.0.1:0")
+	err := s.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Stop(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
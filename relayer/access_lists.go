@@ -0,0 +1,231 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog/log"
+)
+
+const AccessListsProcessorType = "accessLists"
+
+// AccessListsReloadInterval is how often lists file is checked for changes
+var AccessListsReloadInterval = time.Second * 10
+
+// AccessLists are allow and deny lists of a chain, entries are hex encoded. Recipients are checked on messages to the chain,
+// depositors on messages from the chain and resource IDs on both. Empty allow list allows everything that is not denied.
+type AccessLists struct {
+	AllowRecipients []string `json:"allowRecipients"`
+	DenyRecipients  []string `json:"denyRecipients"`
+	AllowDepositors []string `json:"allowDepositors"`
+	DenyDepositors  []string `json:"denyDepositors"`
+	AllowResources  []string `json:"allowResources"`
+	DenyResources   []string `json:"denyResources"`
+}
+
+type accessList struct {
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+func newAccessList(allow, deny []string) (*accessList, error) {
+	l := &accessList{allow: make(map[string]bool), deny: make(map[string]bool)}
+	for _, entry := range allow {
+		b, err := hex.DecodeString(strings.TrimPrefix(entry, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %s: %w", entry, err)
+		}
+		l.allow[hex.EncodeToString(b)] = true
+	}
+	for _, entry := range deny {
+		b, err := hex.DecodeString(strings.TrimPrefix(entry, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %s: %w", entry, err)
+		}
+		l.deny[hex.EncodeToString(b)] = true
+	}
+	return l, nil
+}
+
+// check returns why value is not allowed, or empty string if it is
+func (l *accessList) check(value []byte) string {
+	if l.deny[hex.EncodeToString(value)] {
+		return "denied"
+	}
+	if len(l.allow) != 0 && !l.allow[hex.EncodeToString(value)] {
+		return "not allowed"
+	}
+	return ""
+}
+
+type chainAccessLists struct {
+	recipients *accessList
+	depositors *accessList
+	resources  *accessList
+}
+
+type accessListsProcessor struct {
+	path string
+	now  func() time.Time
+
+	lock      sync.Mutex
+	lists     map[uint8]*chainAccessLists
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+// NewAccessListsProcessor creates processor that skips messages denied by allow and deny lists of their source and destination chains.
+// Lists are loaded from JSON file at path mapping chain IDs to AccessLists, like {"1": {"denyRecipients": ["0xab..."]}},
+// and reloaded when file changes.
+func NewAccessListsProcessor(path string) (MessageProcessor, error) {
+	p := &accessListsProcessor{path: path, now: time.Now}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	err = p.load(info)
+	if err != nil {
+		return nil, err
+	}
+	p.checkedAt = p.now()
+	return p, nil
+}
+
+// NewAccessListsProcessorFromConfig creates access lists processor from chain config entry like {"type": "accessLists", "file": "lists.json"}
+func NewAccessListsProcessorFromConfig(params map[string]interface{}) (MessageProcessor, error) {
+	config := struct {
+		File string `mapstructure:"file"`
+	}{}
+	err := mapstructure.Decode(params, &config)
+	if err != nil {
+		return nil, err
+	}
+	if config.File == "" {
+		return nil, errors.New("lists file is not set")
+	}
+	return NewAccessListsProcessor(config.File)
+}
+
+func (p *accessListsProcessor) Name() string {
+	return AccessListsProcessorType
+}
+
+func (p *accessListsProcessor) Process(m *Message) (ProcessorResult, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.reload()
+
+	for _, chainID := range []uint8{m.Source, m.Destination} {
+		if l, ok := p.lists[chainID]; ok {
+			if reason := l.resources.check(m.ResourceId[:]); reason != "" {
+				return Skip(fmt.Sprintf("resource %x is %s on chain %d", m.ResourceId, reason, chainID)), nil
+			}
+		}
+	}
+	if l, ok := p.lists[m.Source]; ok {
+		if len(m.Depositor) == 0 {
+			if len(l.depositors.allow) != 0 {
+				return Skip(fmt.Sprintf("depositor is unknown and chain %d allows listed depositors only", m.Source)), nil
+			}
+		} else if reason := l.depositors.check(m.Depositor); reason != "" {
+			return Skip(fmt.Sprintf("depositor %x is %s on chain %d", m.Depositor, reason, m.Source)), nil
+		}
+	}
+	if l, ok := p.lists[m.Destination]; ok {
+		recipient, err := messageRecipient(m)
+		if err != nil {
+			return ProcessorResult{}, err
+		}
+		if recipient != nil {
+			if reason := l.recipients.check(recipient); reason != "" {
+				return Skip(fmt.Sprintf("recipient %x is %s on chain %d", recipient, reason, m.Destination)), nil
+			}
+		}
+	}
+	return Continue(), nil
+}
+
+// messageRecipient returns recipient of fungible and non fungible transfers, nil for other messages
+func messageRecipient(m *Message) ([]byte, error) {
+	switch m.Type {
+	case FungibleTransfer:
+		payload, err := m.FungibleTransferPayload()
+		if err != nil {
+			return nil, err
+		}
+		return payload.Recipient, nil
+	case NonFungibleTransfer:
+		payload, err := m.NonFungibleTransferPayload()
+		if err != nil {
+			return nil, err
+		}
+		return payload.Recipient, nil
+	default:
+		return nil, nil
+	}
+}
+
+// reload loads lists again if file changed since it was last checked. Previous lists are kept if file can not be loaded.
+func (p *accessListsProcessor) reload() {
+	if p.now().Sub(p.checkedAt) < AccessListsReloadInterval {
+		return
+	}
+	p.checkedAt = p.now()
+	info, err := os.Stat(p.path)
+	if err != nil {
+		log.Error().Err(err).Msgf("checking access lists file %s, previous lists are kept", p.path)
+		return
+	}
+	if info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return
+	}
+	err = p.load(info)
+	if err != nil {
+		log.Error().Err(err).Msgf("reloading access lists file %s, previous lists are kept", p.path)
+		// Failed file is not loaded again until it changes
+		p.modTime, p.size = info.ModTime(), info.Size()
+		return
+	}
+	log.Info().Msgf("Reloaded access lists from %s", p.path)
+}
+
+func (p *accessListsProcessor) load(info os.FileInfo) error {
+	data, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+	config := make(map[uint8]AccessLists)
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return fmt.Errorf("decoding access lists: %w", err)
+	}
+	lists := make(map[uint8]*chainAccessLists)
+	for chainID, c := range config {
+		l := &chainAccessLists{}
+		if l.recipients, err = newAccessList(c.AllowRecipients, c.DenyRecipients); err != nil {
+			return fmt.Errorf("recipients of chain %d: %w", chainID, err)
+		}
+		if l.depositors, err = newAccessList(c.AllowDepositors, c.DenyDepositors); err != nil {
+			return fmt.Errorf("depositors of chain %d: %w", chainID, err)
+		}
+		if l.resources, err = newAccessList(c.AllowResources, c.DenyResources); err != nil {
+			return fmt.Errorf("resources of chain %d: %w", chainID, err)
+		}
+		lists[chainID] = l
+	}
+	p.lists = lists
+	p.modTime, p.size = info.ModTime(), info.Size()
+	return nil
+}
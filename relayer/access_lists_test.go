@@ -0,0 +1,106 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeLists(t *testing.T, path string, lists string, modTime time.Time) {
+	err := ioutil.WriteFile(path, []byte(lists), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(path, modTime, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestListsProcessor(t *testing.T, lists string) (*accessListsProcessor, string, func()) {
+	dir, err := ioutil.TempDir("", "lists")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "lists.json")
+	writeLists(t, path, lists, time.Now().Add(-time.Hour))
+	p, err := NewAccessListsProcessor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*accessListsProcessor), path, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestAccessLists(t *testing.T) {
+	p, _, cleanup := newTestListsProcessor(t, `{
+		"1": {"denyDepositors": ["0xAA"], "denyResources": ["0x0200000000000000000000000000000000000000000000000000000000000000"]},
+		"2": {"denyRecipients": ["0x02"]}
+	}`)
+	defer cleanup()
+
+	expectOutcome(t, p, transfer(1, 10, 1), OutcomeContinue)
+	expectOutcome(t, p, transfer(2, 10, 2), OutcomeSkip)
+
+	denied := transfer(3, 10, 1)
+	denied.Depositor = []byte{0xaa}
+	expectOutcome(t, p, denied, OutcomeSkip)
+
+	paused := transfer(4, 10, 1)
+	paused.ResourceId = [32]byte{2}
+	expectOutcome(t, p, paused, OutcomeSkip)
+
+	// Recipient is denied on chain 2 only
+	otherChain := transfer(5, 10, 2)
+	otherChain.Destination = 3
+	expectOutcome(t, p, otherChain, OutcomeContinue)
+
+	result, err := p.Process(denied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Reason != "depositor aa is denied on chain 1" {
+		t.Fatalf("unexpected reason %q", result.Reason)
+	}
+}
+
+func TestAccessListsAllowList(t *testing.T) {
+	p, _, cleanup := newTestListsProcessor(t, `{"1": {"allowDepositors": ["0xaa"]}}`)
+	defer cleanup()
+
+	allowed := transfer(1, 10, 1)
+	allowed.Depositor = []byte{0xaa}
+	expectOutcome(t, p, allowed, OutcomeContinue)
+	other := transfer(2, 10, 1)
+	other.Depositor = []byte{0xbb}
+	expectOutcome(t, p, other, OutcomeSkip)
+	// Depositor that is not reported can not be on allow list
+	expectOutcome(t, p, transfer(3, 10, 1), OutcomeSkip)
+}
+
+func TestAccessListsReload(t *testing.T) {
+	p, path, cleanup := newTestListsProcessor(t, `{"2": {"denyRecipients": ["0x01"]}}`)
+	defer cleanup()
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	expectOutcome(t, p, transfer(1, 10, 1), OutcomeSkip)
+
+	writeLists(t, path, `{"2": {"denyRecipients": ["0x02"]}}`, time.Now())
+	// File is not checked before reload interval passes
+	expectOutcome(t, p, transfer(1, 10, 1), OutcomeSkip)
+	now = now.Add(AccessListsReloadInterval)
+	expectOutcome(t, p, transfer(1, 10, 1), OutcomeContinue)
+	expectOutcome(t, p, transfer(2, 10, 2), OutcomeSkip)
+
+	// Invalid file keeps previous lists
+	writeLists(t, path, `{"2": {"denyRecipients": ["not hex"]}}`, time.Now().Add(time.Minute))
+	now = now.Add(AccessListsReloadInterval)
+	expectOutcome(t, p, transfer(2, 10, 2), OutcomeSkip)
+}
@@ -4,8 +4,8 @@
 package relayer
 
 import (
-	"errors"
-	"math/big"
+	"bytes"
+	"fmt"
 )
 
 type TransferType string
@@ -30,36 +30,63 @@ var (
 	StatusMap = map[ProposalStatus]string{ProposalStatusInactive: "inactive", ProposalStatusActive: "active", ProposalStatusPassed: "passed", ProposalStatusExecuted: "executed", ProposalStatusCanceled: "canceled"}
 )
 
+type MessageStatus uint8
+
+const (
+	MessageStatusPending     MessageStatus = iota // Read from source chain, not yet written to destination
+	MessageStatusDone                             // Successfully written to destination chain
+	MessageStatusSkipped                          // Skipped by message processor, not written to destination chain
+	MessageStatusQuarantined                      // Held by message processor until it is approved
+	MessageStatusApproved                         // Quarantined message approved to be written to destination chain
+)
+
+var messageStatusNames = map[MessageStatus]string{
+	MessageStatusPending:     "pending",
+	MessageStatusDone:        "done",
+	MessageStatusSkipped:     "skipped",
+	MessageStatusQuarantined: "quarantined",
+	MessageStatusApproved:    "approved",
+}
+
+func (s MessageStatus) String() string {
+	if name, ok := messageStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown status %d", s)
+}
+
 type Message struct {
 	Source       uint8  // Source where message was initiated
 	Destination  uint8  // Destination chain of message
 	DepositNonce uint64 // Nonce for the deposit
 	ResourceId   [32]byte
-	Payload      []interface{} // data associated with event sequence
+	Payload      Payload // data associated with event sequence
 	Type         TransferType
+	Depositor    []byte // Address of depositor on source chain, empty if source chain does not report it
 }
 
-// extractAmountTransferred is a private method to extract and transform the transfer amount
-// from the Payload field within the Message struct
-func (m *Message) extractAmountTransferred() (float64, error) {
-	// parse payload field from event log message to obtain transfer amount
-	// payload slice of interfaces includes..
-	// index 0: amount ([]byte)
-	// index 1: destination recipient address ([]byte)
-
-	// declare new float64 as return value
-	var payloadAmountFloat float64
+// copy returns copy of message, payload is shared
+func (m *Message) copy() *Message {
+	c := *m
+	return &c
+}
 
-	// cast interface to byte slice
-	amountByteSlice, ok := m.Payload[0].([]byte)
-	if !ok {
-		err := errors.New("could not cast interface to byte slice")
-		return payloadAmountFloat, err
+// sameDeposit reports whether m and other describe the same deposit, ignoring their routing fields
+func (m *Message) sameDeposit(other *Message) bool {
+	if m.ResourceId != other.ResourceId || m.Type != other.Type || !bytes.Equal(m.Depositor, other.Depositor) {
+		return false
 	}
-
-	// convert big int => float64
-	// ignore accuracy (rounding)
-	payloadAmountFloat, _ = new(big.Float).SetInt(big.NewInt(0).SetBytes(amountByteSlice)).Float64()
-
-	return payloadAmountFloat, nil
+	if m.Payload == nil || other.Payload == nil {
+		return m.Payload == nil && other.Payload == nil
+	}
+	a, b := m.Payload.Elements(), other.Payload.Elements()
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
 }
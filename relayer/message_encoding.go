@@ -0,0 +1,228 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MessageEncodingVersion is the version of Message binary and JSON encoding written by this relayer
+const MessageEncodingVersion uint8 = 1
+
+var ErrUnsupportedMessageVersion = errors.New("unsupported message encoding version")
+
+// MarshalBinary encodes message as version byte followed by source, destination, big endian deposit nonce, resource ID,
+// length prefixed transfer type, payload elements and depositor. Payload is encoded only if payload type is registered
+// for message transfer type, so that it can be decoded.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	elements, err := m.payloadElements()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding payload: %w", err)
+	}
+	buf := bytes.Buffer{}
+	buf.WriteByte(MessageEncodingVersion)
+	buf.WriteByte(m.Source)
+	buf.WriteByte(m.Destination)
+	nonce := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonce, m.DepositNonce)
+	buf.Write(nonce)
+	buf.Write(m.ResourceId[:])
+	writeBytes(&buf, []byte(m.Type))
+	writeUvarint(&buf, uint64(len(elements)))
+	for _, b := range elements {
+		writeBytes(&buf, b)
+	}
+	writeBytes(&buf, m.Depositor)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes message encoded by MarshalBinary
+func (m *Message) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	header := make([]byte, 1+1+1+8+32)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return fmt.Errorf("error decoding message header: %w", err)
+	}
+	if header[0] != MessageEncodingVersion {
+		return fmt.Errorf("%w %d", ErrUnsupportedMessageVersion, header[0])
+	}
+	msg := Message{
+		Source:       header[1],
+		Destination:  header[2],
+		DepositNonce: binary.BigEndian.Uint64(header[3:11]),
+	}
+	copy(msg.ResourceId[:], header[11:])
+	transferType, err := readBytes(r)
+	if err != nil {
+		return fmt.Errorf("error decoding transfer type: %w", err)
+	}
+	msg.Type = TransferType(transferType)
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("error decoding payload length: %w", err)
+	}
+	// Every element takes at least one byte, bigger count can only come from corrupted data
+	if count > uint64(r.Len()) {
+		return fmt.Errorf("payload length %d exceeds message size", count)
+	}
+	elements := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		b, err := readBytes(r)
+		if err != nil {
+			return fmt.Errorf("error decoding payload element %d: %w", i, err)
+		}
+		elements = append(elements, b)
+	}
+	msg.Payload, err = decodePayloadElements(msg.Type, elements)
+	if err != nil {
+		return err
+	}
+	depositor, err := readBytes(r)
+	if err != nil {
+		return fmt.Errorf("error decoding depositor: %w", err)
+	}
+	if len(depositor) > 0 {
+		msg.Depositor = depositor
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%d unexpected bytes after message", r.Len())
+	}
+	*m = msg
+	return nil
+}
+
+// decodePayloadElements decodes payload of transfer type t from its elements, message without elements has no payload
+func decodePayloadElements(t TransferType, elements [][]byte) (Payload, error) {
+	if len(elements) == 0 {
+		return nil, nil
+	}
+	p, err := DecodePayload(t, elements)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding payload: %w", err)
+	}
+	return p, nil
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	buf.Write(b[:binary.PutUvarint(b, v)])
+}
+
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	writeUvarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > uint64(r.Len()) {
+		return nil, fmt.Errorf("length %d exceeds remaining %d bytes", l, r.Len())
+	}
+	b := make([]byte, l)
+	_, err = io.ReadFull(r, b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+type jsonMessage struct {
+	Version      uint8        `json:"version"`
+	Source       uint8        `json:"source"`
+	Destination  uint8        `json:"destination"`
+	DepositNonce uint64       `json:"depositNonce"`
+	ResourceId   hexBytes     `json:"resourceId"`
+	Type         TransferType `json:"type"`
+	Payload      []hexBytes   `json:"payload"`
+	Depositor    hexBytes     `json:"depositor,omitempty"`
+}
+
+// MarshalJSON encodes message as JSON object with byte fields and payload elements encoded as 0x prefixed hex strings.
+// Payload is encoded only if payload type is registered for message transfer type, so that it can be decoded.
+func (m *Message) MarshalJSON() ([]byte, error) {
+	elements, err := m.payloadElements()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding payload: %w", err)
+	}
+	jm := jsonMessage{
+		Version:      MessageEncodingVersion,
+		Source:       m.Source,
+		Destination:  m.Destination,
+		DepositNonce: m.DepositNonce,
+		ResourceId:   m.ResourceId[:],
+		Type:         m.Type,
+		Payload:      []hexBytes{},
+		Depositor:    m.Depositor,
+	}
+	for _, b := range elements {
+		jm.Payload = append(jm.Payload, b)
+	}
+	return json.Marshal(jm)
+}
+
+// UnmarshalJSON decodes message encoded by MarshalJSON
+func (m *Message) UnmarshalJSON(data []byte) error {
+	jm := jsonMessage{}
+	err := json.Unmarshal(data, &jm)
+	if err != nil {
+		return err
+	}
+	if jm.Version != MessageEncodingVersion {
+		return fmt.Errorf("%w %d", ErrUnsupportedMessageVersion, jm.Version)
+	}
+	if len(jm.ResourceId) != 32 {
+		return fmt.Errorf("resource ID should be 32 bytes, got %d", len(jm.ResourceId))
+	}
+	msg := Message{
+		Source:       jm.Source,
+		Destination:  jm.Destination,
+		DepositNonce: jm.DepositNonce,
+		Type:         jm.Type,
+	}
+	copy(msg.ResourceId[:], jm.ResourceId)
+	elements := make([][]byte, 0, len(jm.Payload))
+	for _, b := range jm.Payload {
+		elements = append(elements, b)
+	}
+	msg.Payload, err = decodePayloadElements(msg.Type, elements)
+	if err != nil {
+		return err
+	}
+	if len(jm.Depositor) > 0 {
+		msg.Depositor = jm.Depositor
+	}
+	*m = msg
+	return nil
+}
+
+// hexBytes is JSON encoded as 0x prefixed hex string
+type hexBytes []byte
+
+func (b hexBytes) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(b)), nil
+}
+
+func (b *hexBytes) UnmarshalText(text []byte) error {
+	s := string(text)
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("hex string %q without 0x prefix", s)
+	}
+	dec, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return err
+	}
+	*b = dec
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func randomBytes(r *rand.Rand) []byte {
+	b := make([]byte, r.Intn(100))
+	r.Read(b)
+	return b
+}
+
+// randomMessage returns message of random transfer type, custom transfer type has to be registered by registerCustomPayload
+func randomMessage(r *rand.Rand) *Message {
+	m := &Message{
+		Source:       uint8(r.Intn(256)),
+		Destination:  uint8(r.Intn(256)),
+		DepositNonce: r.Uint64(),
+		Type:         []TransferType{FungibleTransfer, NonFungibleTransfer, GenericTransfer, "", customTransfer}[r.Intn(5)],
+	}
+	r.Read(m.ResourceId[:])
+	switch m.Type {
+	case FungibleTransfer:
+		m.Payload = &FungibleTransferPayload{Amount: new(big.Int).SetBytes(randomBytes(r)), Recipient: randomBytes(r)}
+	case NonFungibleTransfer:
+		m.Payload = &NonFungibleTransferPayload{TokenID: new(big.Int).SetBytes(randomBytes(r)), Recipient: randomBytes(r), Metadata: randomBytes(r)}
+	case GenericTransfer:
+		m.Payload = &GenericTransferPayload{Metadata: randomBytes(r)}
+	case customTransfer:
+		custom := &customPayload{}
+		for i := 1 + r.Intn(4); i > 0; i-- {
+			custom.Data = append(custom.Data, randomBytes(r))
+		}
+		m.Payload = custom
+	}
+	if r.Intn(2) == 0 {
+		m.Depositor = make([]byte, 1+r.Intn(32))
+		r.Read(m.Depositor)
+	}
+	return m
+}
+
+// expectSameMessage fails test if decoded message differs from m, payload is compared by its elements
+func expectSameMessage(t *testing.T, m, decoded *Message) {
+	t.Helper()
+	if m.Source != decoded.Source || m.Destination != decoded.Destination || m.DepositNonce != decoded.DepositNonce ||
+		reflect.TypeOf(m.Payload) != reflect.TypeOf(decoded.Payload) || !m.sameDeposit(decoded) ||
+		(m.Depositor == nil) != (decoded.Depositor == nil) {
+		t.Fatalf("decoded message does not match\ngot: %+v\nexpected: %+v", decoded, m)
+	}
+}
+
+func TestMessageBinaryRoundTrip(t *testing.T) {
+	registerCustomPayload()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		m := randomMessage(r)
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := &Message{}
+		err = decoded.UnmarshalBinary(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectSameMessage(t, m, decoded)
+	}
+}
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	registerCustomPayload()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		m := randomMessage(r)
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := &Message{}
+		err = json.Unmarshal(data, decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectSameMessage(t, m, decoded)
+	}
+}
+
+// TestMessageUnmarshalBinaryCorrupted checks that truncated and mutated messages are rejected without panics
+func TestMessageUnmarshalBinaryCorrupted(t *testing.T) {
+	registerCustomPayload()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		data, err := randomMessage(r).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = (&Message{}).UnmarshalBinary(data[:r.Intn(len(data))])
+		if err == nil {
+			t.Fatal("expected error for truncated message")
+		}
+		data[r.Intn(len(data))] = byte(r.Intn(256))
+		_ = (&Message{}).UnmarshalBinary(data)
+		_ = (&Message{}).UnmarshalBinary(append(data, byte(r.Intn(256))))
+	}
+}
+
+func TestMessageJSON(t *testing.T) {
+	m := &Message{
+		Source:       1,
+		Destination:  2,
+		DepositNonce: 3,
+		ResourceId:   [32]byte{0xab},
+		Type:         FungibleTransfer,
+		Payload:      &FungibleTransferPayload{Amount: big.NewInt(10), Recipient: []byte{0x01, 0x02}},
+		Depositor:    []byte{0x03},
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"version":1,"source":1,"destination":2,"depositNonce":3,` +
+		`"resourceId":"0xab00000000000000000000000000000000000000000000000000000000000000",` +
+		`"type":"FungibleTransfer","payload":["0x0a","0x0102"],"depositor":"0x03"}`
+	if string(data) != expected {
+		t.Fatalf("unexpected JSON\ngot: %s\nexpected: %s", data, expected)
+	}
+}
+
+func TestMessageUnsupportedVersion(t *testing.T) {
+	data, err := (&Message{}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0] = MessageEncodingVersion + 1
+	err = (&Message{}).UnmarshalBinary(data)
+	if !errors.Is(err, ErrUnsupportedMessageVersion) {
+		t.Fatalf("expected unsupported version error, got %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"version":2,"resourceId":"0x00"}`), &Message{})
+	if !errors.Is(err, ErrUnsupportedMessageVersion) {
+		t.Fatalf("expected unsupported version error, got %v", err)
+	}
+}
+
+func TestMessageEncodingRejectsUnregisteredPayload(t *testing.T) {
+	for _, m := range []*Message{
+		{Type: "Unregistered", Payload: &customPayload{Data: [][]byte{{1}}}},
+		{Type: FungibleTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}},
+	} {
+		_, err := m.MarshalBinary()
+		if err == nil {
+			t.Fatalf("expected error encoding %T payload of transfer type %s", m.Payload, m.Type)
+		}
+		_, err = json.Marshal(m)
+		if err == nil {
+			t.Fatalf("expected error encoding %T payload of transfer type %s to JSON", m.Payload, m.Type)
+		}
+	}
+	_, err := (&Message{Type: "Unregistered", Payload: &customPayload{Data: [][]byte{{1}}}}).MarshalBinary()
+	if !errors.Is(err, ErrUnregisteredPayloadType) {
+		t.Fatalf("expected unregistered payload type error, got %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"version":1,"resourceId":"0x0000000000000000000000000000000000000000000000000000000000000000","type":"Unregistered","payload":["0x01"]}`), &Message{})
+	if !errors.Is(err, ErrUnregisteredPayloadType) {
+		t.Fatalf("expected unregistered payload type error, got %v", err)
+	}
+}
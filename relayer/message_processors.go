@@ -1,51 +1,245 @@
 package relayer
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
+	"sync"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/rs/zerolog/log"
 )
 
-// AdjustDecimalsForERC20AmountMessageProcessor is a function, that accepts message and map[chainID uint8]{decimal uint}
-// using this  params processor converts amount for one chain to another for provided decimals with floor rounding
-func AdjustDecimalsForERC20AmountMessageProcessor(args ...interface{}) MessageProcessor {
-	return func(m *Message) error {
-		if len(args) == 0 {
-			return errors.New("processor requires 1 argument")
-		}
-		decimalsMap, ok := args[0].(map[uint8]uint64)
-		if !ok {
-			return errors.New("no decimals map found in args")
-		}
-		sourceDecimal, ok := decimalsMap[m.Source]
-		if !ok {
-			return errors.New("no source decimals found at decimalsMap")
-		}
-		destDecimal, ok := decimalsMap[m.Destination]
-		if !ok {
-			return errors.New("no destination decimals found at decimalsMap")
+const AdjustDecimalsProcessorType = "adjustDecimals"
+
+// DustPolicy tells adjust decimals processor what to do with transfer that would lose part of amount when
+// converted to destination chain with fewer decimals
+type DustPolicy string
+
+const (
+	DustReject     DustPolicy = "reject"     // Transfer is skipped
+	DustQuarantine DustPolicy = "quarantine" // Transfer is quarantined, approving it relays amount rounded down
+)
+
+// DecimalsDiscoverer is implemented by chains that look up decimals of token registered for a resource.
+// Discoverer returns error wrapping ErrDecimalsUnavailable if retrying lookup does not help, other errors are
+// treated as temporary.
+type DecimalsDiscoverer interface {
+	TokenDecimals(resourceID [32]byte) (uint64, error)
+}
+
+// DecimalsDiscoverersSetter is implemented by processors that discover decimals of tokens. When relayer starts it passes
+// them its chains that implement DecimalsDiscoverer, mapped by chain ID.
+type DecimalsDiscoverersSetter interface {
+	SetDecimalsDiscoverers(discoverers map[uint8]DecimalsDiscoverer)
+}
+
+var errDecimalsDiscovery = errors.New("decimals discovery failed")
+
+// ErrDecimalsUnavailable means that chain cannot provide decimals of resource, e.g. no token is registered for it
+var ErrDecimalsUnavailable = errors.New("decimals are not available")
+
+// DecimalsConfig configures decimals of tokens. Decimals of resource on chain are taken from Resources,
+// then discovered from chain if Discover is set, then taken from Chains. Chains are also used if chain reports
+// that decimals of resource are not available.
+type DecimalsConfig struct {
+	// Chains are decimals of all resources on chain, mapped by chain ID
+	Chains map[uint8]uint64
+	// Resources are decimals of resource on chain, mapped by resource ID and chain ID
+	Resources map[[32]byte]map[uint8]uint64
+	// Discover looks up decimals of tokens on chains that have discoverer
+	Discover bool
+	// Discoverers of decimals mapped by chain ID, discoverers set by relayer do not replace them
+	Discoverers map[uint8]DecimalsDiscoverer
+	// Dust is DustReject if not set
+	Dust DustPolicy
+}
+
+type decimalsKey struct {
+	chainID    uint8
+	resourceID [32]byte
+}
+
+type adjustDecimalsProcessor struct {
+	config DecimalsConfig
+
+	lock        sync.Mutex
+	discoverers map[uint8]DecimalsDiscoverer
+	discovered  map[decimalsKey]uint64
+}
+
+// NewAdjustDecimalsProcessor creates processor that converts amount of fungible transfer from source chain decimals
+// to destination chain decimals. Decimals are mapped by chain ID. Transfers that would lose part of amount are skipped.
+func NewAdjustDecimalsProcessor(decimals map[uint8]uint64) MessageProcessor {
+	return NewAdjustDecimalsProcessorWithConfig(DecimalsConfig{Chains: decimals})
+}
+
+// NewAdjustDecimalsProcessorWithConfig creates processor that converts amount of fungible transfer from decimals of
+// resource on source chain to its decimals on destination chain
+func NewAdjustDecimalsProcessorWithConfig(config DecimalsConfig) MessageProcessor {
+	if config.Dust == "" {
+		config.Dust = DustReject
+	}
+	discoverers := make(map[uint8]DecimalsDiscoverer)
+	for chainID, d := range config.Discoverers {
+		discoverers[chainID] = d
+	}
+	return &adjustDecimalsProcessor{config: config, discoverers: discoverers, discovered: make(map[decimalsKey]uint64)}
+}
+
+// NewAdjustDecimalsProcessorFromConfig creates adjust decimals processor from chain config entry like
+// {"type": "adjustDecimals", "decimals": {"1": 18, "2": 6}, "resources": {"0x00..01": {"1": 18, "2": 8}}, "discover": true, "dust": "quarantine"}
+func NewAdjustDecimalsProcessorFromConfig(params map[string]interface{}) (MessageProcessor, error) {
+	config := struct {
+		Decimals  map[uint8]uint64            `mapstructure:"decimals"`
+		Resources map[string]map[uint8]uint64 `mapstructure:"resources"`
+		Discover  bool                        `mapstructure:"discover"`
+		Dust      string                      `mapstructure:"dust"`
+	}{}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{WeaklyTypedInput: true, Result: &config})
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Decimals) == 0 && len(config.Resources) == 0 && !config.Discover {
+		return nil, errors.New("decimals are not configured")
+	}
+	dust := DustPolicy(config.Dust)
+	if dust != "" && dust != DustReject && dust != DustQuarantine {
+		return nil, fmt.Errorf("unknown dust policy %s", config.Dust)
+	}
+	resources := make(map[[32]byte]map[uint8]uint64)
+	for rID, decimals := range config.Resources {
+		b, err := hex.DecodeString(strings.TrimPrefix(rID, "0x"))
+		if err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("invalid resource ID %s", rID)
 		}
-		amountByte, ok := m.Payload[0].([]byte)
-		if !ok {
-			return errors.New("could not cast interface to byte slice")
+		var resourceID [32]byte
+		copy(resourceID[:], b)
+		resources[resourceID] = decimals
+	}
+	return NewAdjustDecimalsProcessorWithConfig(DecimalsConfig{
+		Chains:    config.Decimals,
+		Resources: resources,
+		Discover:  config.Discover,
+		Dust:      dust,
+	}), nil
+}
+
+func (p *adjustDecimalsProcessor) Name() string {
+	return AdjustDecimalsProcessorType
+}
+
+func (p *adjustDecimalsProcessor) SetDecimalsDiscoverers(discoverers map[uint8]DecimalsDiscoverer) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for chainID, d := range discoverers {
+		if _, ok := p.config.Discoverers[chainID]; !ok {
+			p.discoverers[chainID] = d
 		}
-		amount := new(big.Int).SetBytes(amountByte)
-		if sourceDecimal > destDecimal {
-			diff := sourceDecimal - destDecimal
-			roundedAmount := big.NewInt(0)
-			roundedAmount.Div(amount, big.NewInt(0).Exp(big.NewInt(10), big.NewInt(0).SetUint64(diff), nil))
-			log.Info().Msgf("amount %s rounded to %s from chain %v to chain %v", amount.String(), roundedAmount.String(), m.Source, m.Destination)
-			m.Payload[0] = roundedAmount.Bytes()
-			return nil
+	}
+}
+
+func (p *adjustDecimalsProcessor) Process(m *Message) (ProcessorResult, error) {
+	if m.Type != FungibleTransfer {
+		return Continue(), nil
+	}
+	// Temporary discovery failures are retried later, e.g. chain endpoint is not reachable
+	sourceDecimal, err := p.decimals(m.Source, m.ResourceId)
+	if errors.Is(err, errDecimalsDiscovery) {
+		return RetryLater(fmt.Sprintf("decimals of source chain %d: %s", m.Source, err), 0), nil
+	}
+	if err != nil {
+		return ProcessorResult{}, fmt.Errorf("decimals of source chain %d: %w", m.Source, err)
+	}
+	destDecimal, err := p.decimals(m.Destination, m.ResourceId)
+	if errors.Is(err, errDecimalsDiscovery) {
+		return RetryLater(fmt.Sprintf("decimals of destination chain %d: %s", m.Destination, err), 0), nil
+	}
+	if err != nil {
+		return ProcessorResult{}, fmt.Errorf("decimals of destination chain %d: %w", m.Destination, err)
+	}
+	if sourceDecimal == destDecimal {
+		return Continue(), nil
+	}
+	payload, err := m.FungibleTransferPayload()
+	if err != nil {
+		return ProcessorResult{}, err
+	}
+	roundedAmount := big.NewInt(0)
+	dust := big.NewInt(0)
+	if sourceDecimal > destDecimal {
+		diff := sourceDecimal - destDecimal
+		roundedAmount.DivMod(payload.Amount, big.NewInt(0).Exp(big.NewInt(10), big.NewInt(0).SetUint64(diff), nil), dust)
+	} else {
+		diff := destDecimal - sourceDecimal
+		roundedAmount.Mul(payload.Amount, big.NewInt(0).Exp(big.NewInt(10), big.NewInt(0).SetUint64(diff), nil))
+	}
+	log.Info().Msgf("amount %s rounded to %s from chain %v to chain %v", payload.Amount.String(), roundedAmount.String(), m.Source, m.Destination)
+	m.Payload = &FungibleTransferPayload{Amount: roundedAmount, Recipient: payload.Recipient}
+	if dust.Sign() == 0 {
+		return Modified(), nil
+	}
+	reason := fmt.Sprintf("amount loses dust %s converting from %d to %d decimals", dust, sourceDecimal, destDecimal)
+	if p.config.Dust == DustQuarantine {
+		return Quarantine(reason), nil
+	}
+	return Skip(reason), nil
+}
+
+func (p *adjustDecimalsProcessor) decimals(chainID uint8, resourceID [32]byte) (uint64, error) {
+	if decimals, ok := p.config.Resources[resourceID][chainID]; ok {
+		return decimals, nil
+	}
+	var discoveryErr error
+	if p.config.Discover {
+		p.lock.Lock()
+		d, ok := p.discoverers[chainID]
+		p.lock.Unlock()
+		if ok {
+			decimals, err := p.discover(d, chainID, resourceID)
+			if !errors.Is(err, ErrDecimalsUnavailable) {
+				return decimals, err
+			}
+			discoveryErr = err
 		}
-		if sourceDecimal < destDecimal {
-			diff := destDecimal - sourceDecimal
-			roundedAmount := big.NewInt(0)
-			roundedAmount.Mul(amount, big.NewInt(0).Exp(big.NewInt(10), big.NewInt(0).SetUint64(diff), nil))
-			m.Payload[0] = roundedAmount.Bytes()
-			log.Info().Msgf("amount %s rounded to %s from chain %v to chain %v", amount.String(), roundedAmount.String(), m.Source, m.Destination)
+	}
+	if decimals, ok := p.config.Chains[chainID]; ok {
+		if discoveryErr != nil {
+			log.Warn().Err(discoveryErr).Uint8("chainID", chainID).Msgf("Using configured %d decimals of chain", decimals)
 		}
-		return nil
+		return decimals, nil
+	}
+	if discoveryErr != nil {
+		return 0, discoveryErr
+	}
+	return 0, fmt.Errorf("no decimals of resource %x", resourceID)
+}
+
+// discover looks up decimals once, errors are not cached so lookup is repeated when message is retried
+func (p *adjustDecimalsProcessor) discover(d DecimalsDiscoverer, chainID uint8, resourceID [32]byte) (uint64, error) {
+	key := decimalsKey{chainID: chainID, resourceID: resourceID}
+	p.lock.Lock()
+	decimals, ok := p.discovered[key]
+	p.lock.Unlock()
+	if ok {
+		return decimals, nil
+	}
+	decimals, err := d.TokenDecimals(resourceID)
+	if errors.Is(err, ErrDecimalsUnavailable) {
+		return 0, fmt.Errorf("resource %x: %w", resourceID, err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w for resource %x: %s", errDecimalsDiscovery, resourceID, err)
 	}
+	log.Info().Uint8("chainID", chainID).Msgf("Discovered %d decimals of resource %x", decimals, resourceID)
+	p.lock.Lock()
+	p.discovered[key] = decimals
+	p.lock.Unlock()
+	return decimals, nil
 }
@@ -0,0 +1,270 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestAdjustDecimalsProcessor(t *testing.T) {
+	a, _ := big.NewInt(0).SetString("145550000000000000000", 10) // 145.55 tokens
+	msg := &Message{
+		Destination: 2,
+		Source:      1,
+		Type:        FungibleTransfer,
+		Payload:     &FungibleTransferPayload{Amount: a, Recipient: []byte{1}},
+	}
+	result, err := NewAdjustDecimalsProcessor(map[uint8]uint64{1: 18, 2: 2}).Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := msg.FungibleTransferPayload()
+	if result.Outcome != OutcomeModified || payload.Amount.Cmp(big.NewInt(14555)) != 0 {
+		t.Fatalf("unexpected outcome %s and amount %s", result.Outcome, payload.Amount)
+	}
+
+	msg2 := &Message{
+		Destination: 1,
+		Source:      2,
+		Type:        FungibleTransfer,
+		Payload:     &FungibleTransferPayload{Amount: big.NewInt(14555), Recipient: []byte{1}}, // 145.55 tokens from 2nd chain
+	}
+	result, err = NewAdjustDecimalsProcessor(map[uint8]uint64{1: 18, 2: 2}).Process(msg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, _ := big.NewInt(0).SetString("145550000000000000000", 10)
+	payload, _ = msg2.FungibleTransferPayload()
+	if result.Outcome != OutcomeModified || payload.Amount.Cmp(a2) != 0 {
+		t.Fatalf("unexpected outcome %s and amount %s", result.Outcome, payload.Amount)
+	}
+}
+
+func TestAdjustDecimalsProcessorDust(t *testing.T) {
+	a, _ := big.NewInt(0).SetString("145556700000000000000", 10) // 145.5567 tokens
+	newMessage := func() *Message {
+		return &Message{Destination: 2, Source: 1, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: a, Recipient: []byte{1}}}
+	}
+
+	result, err := NewAdjustDecimalsProcessor(map[uint8]uint64{1: 18, 2: 2}).Process(newMessage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeSkip || result.Reason != "amount loses dust 6700000000000000 converting from 18 to 2 decimals" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	msg := newMessage()
+	p := NewAdjustDecimalsProcessorWithConfig(DecimalsConfig{Chains: map[uint8]uint64{1: 18, 2: 2}, Dust: DustQuarantine})
+	result, err = p.Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Approving quarantined transfer relays rounded amount
+	payload, _ := msg.FungibleTransferPayload()
+	if result.Outcome != OutcomeQuarantine || payload.Amount.Cmp(big.NewInt(14555)) != 0 {
+		t.Fatalf("unexpected outcome %s and amount %s", result.Outcome, payload.Amount)
+	}
+}
+
+type mockDiscoverer struct {
+	decimals map[[32]byte]uint64
+	calls    int
+	// if set, decimals of resources without decimals are unavailable instead of failing to be looked up
+	unavailable bool
+}
+
+func (d *mockDiscoverer) TokenDecimals(resourceID [32]byte) (uint64, error) {
+	d.calls++
+	decimals, ok := d.decimals[resourceID]
+	if !ok && d.unavailable {
+		return 0, fmt.Errorf("%w: no token registered", ErrDecimalsUnavailable)
+	}
+	if !ok {
+		return 0, errors.New("connection refused")
+	}
+	return decimals, nil
+}
+
+func TestAdjustDecimalsProcessorResourceDecimals(t *testing.T) {
+	discoverer := &mockDiscoverer{decimals: map[[32]byte]uint64{{1}: 6, {2}: 8}}
+	p := NewAdjustDecimalsProcessorWithConfig(DecimalsConfig{
+		Chains:    map[uint8]uint64{11: 18},
+		Resources: map[[32]byte]map[uint8]uint64{{2}: {11: 8}},
+		Discover:  true,
+	})
+	p.(DecimalsDiscoverersSetter).SetDecimalsDiscoverers(map[uint8]DecimalsDiscoverer{12: discoverer})
+
+	// Resource 1 has 18 decimals on chain 11 and discovered 6 decimals on chain 12
+	msg := &Message{Source: 11, Destination: 12, ResourceId: [32]byte{1}, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(3000000000000), Recipient: []byte{1}}}
+	result, err := p.Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := msg.FungibleTransferPayload()
+	if result.Outcome != OutcomeModified || payload.Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("unexpected outcome %s and amount %s", result.Outcome, payload.Amount)
+	}
+
+	// Resource 2 has 8 decimals on both chains
+	msg = &Message{Source: 11, Destination: 12, ResourceId: [32]byte{2}, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(3), Recipient: []byte{1}}}
+	result, err = p.Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeContinue {
+		t.Fatalf("unexpected outcome %s", result.Outcome)
+	}
+	if discoverer.calls != 2 {
+		t.Fatalf("expected decimals to be discovered once per resource, got %d calls", discoverer.calls)
+	}
+	_, err = p.Process(msg)
+	if err != nil || discoverer.calls != 2 {
+		t.Fatalf("expected discovered decimals to be cached, got %d calls", discoverer.calls)
+	}
+
+	// Discovery is retried later
+	msg.ResourceId = [32]byte{3}
+	result, err = p.Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeRetryLater || discoverer.calls != 3 {
+		t.Fatalf("expected failed discovery to be retried later, got outcome %s and %d calls", result.Outcome, discoverer.calls)
+	}
+	discoverer.decimals[[32]byte{3}] = 6
+	msg = &Message{Source: 11, Destination: 12, ResourceId: [32]byte{3}, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(3000000000000), Recipient: []byte{1}}}
+	result, err = p.Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeModified || discoverer.calls != 4 {
+		t.Fatalf("expected decimals to be discovered on retry, got outcome %s and %d calls", result.Outcome, discoverer.calls)
+	}
+}
+
+func TestAdjustDecimalsProcessorUnavailableDecimals(t *testing.T) {
+	discoverer := &mockDiscoverer{unavailable: true}
+	p := NewAdjustDecimalsProcessorWithConfig(DecimalsConfig{
+		Chains:   map[uint8]uint64{11: 18, 12: 6},
+		Discover: true,
+	})
+	p.(DecimalsDiscoverersSetter).SetDecimalsDiscoverers(map[uint8]DecimalsDiscoverer{12: discoverer, 13: discoverer})
+
+	// Decimals of chain are used when resource decimals are not available
+	msg := &Message{Source: 11, Destination: 12, ResourceId: [32]byte{1}, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(3000000000000), Recipient: []byte{1}}}
+	result, err := p.Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := msg.FungibleTransferPayload()
+	if result.Outcome != OutcomeModified || payload.Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("unexpected outcome %s and amount %s", result.Outcome, payload.Amount)
+	}
+
+	// Processing fails without decimals of chain, it is not retried later
+	msg = &Message{Source: 11, Destination: 13, ResourceId: [32]byte{1}, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(3), Recipient: []byte{1}}}
+	_, err = p.Process(msg)
+	if !errors.Is(err, ErrDecimalsUnavailable) {
+		t.Fatalf("expected unavailable decimals error, got %v", err)
+	}
+}
+
+func TestAdjustDecimalsProcessorConfiguredDiscoverers(t *testing.T) {
+	configured := &mockDiscoverer{decimals: map[[32]byte]uint64{{1}: 6}}
+	chain := &mockDiscoverer{decimals: map[[32]byte]uint64{{1}: 18}}
+	p := NewAdjustDecimalsProcessorWithConfig(DecimalsConfig{
+		Chains:      map[uint8]uint64{11: 18},
+		Discover:    true,
+		Discoverers: map[uint8]DecimalsDiscoverer{12: configured},
+	})
+	// Discoverers set by relayer do not replace configured ones
+	p.(DecimalsDiscoverersSetter).SetDecimalsDiscoverers(map[uint8]DecimalsDiscoverer{12: chain})
+
+	msg := &Message{Source: 11, Destination: 12, ResourceId: [32]byte{1}, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(3000000000000), Recipient: []byte{1}}}
+	result, err := p.Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := msg.FungibleTransferPayload()
+	if result.Outcome != OutcomeModified || payload.Amount.Cmp(big.NewInt(3)) != 0 || chain.calls != 0 {
+		t.Fatalf("unexpected outcome %s, amount %s and %d calls of chain discoverer", result.Outcome, payload.Amount, chain.calls)
+	}
+}
+
+func TestAdjustDecimalsProcessorSkipsOtherTransfers(t *testing.T) {
+	msg := &Message{Destination: 2, Source: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}
+	result, err := NewAdjustDecimalsProcessor(map[uint8]uint64{}).Process(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeContinue {
+		t.Fatalf("unexpected outcome %s", result.Outcome)
+	}
+}
+
+func TestAdjustDecimalsProcessorFromConfig(t *testing.T) {
+	// viper decodes JSON numbers as float64 and object keys as strings
+	p, err := NewMessageProcessor(AdjustDecimalsProcessorType, map[string]interface{}{
+		"decimals": map[string]interface{}{"1": float64(18), "2": float64(2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := p.(*adjustDecimalsProcessor).config
+	if len(config.Chains) != 2 || config.Chains[1] != 18 || config.Chains[2] != 2 || config.Dust != DustReject {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	p, err = NewMessageProcessor(AdjustDecimalsProcessorType, map[string]interface{}{
+		"resources": map[string]interface{}{
+			"0x0100000000000000000000000000000000000000000000000000000000000000": map[string]interface{}{"1": float64(6)},
+		},
+		"discover": true,
+		"dust":     "quarantine",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = p.(*adjustDecimalsProcessor).config
+	if config.Resources[[32]byte{1}][1] != 6 || !config.Discover || config.Dust != DustQuarantine {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	_, err = NewMessageProcessor(AdjustDecimalsProcessorType, map[string]interface{}{})
+	if err == nil {
+		t.Fatal("expected error for missing decimals")
+	}
+	_, err = NewMessageProcessor(AdjustDecimalsProcessorType, map[string]interface{}{"discover": true, "dust": "ignore"})
+	if err == nil {
+		t.Fatal("expected error for unknown dust policy")
+	}
+}
+
+type discoveringChain struct {
+	mockChain
+	mockDiscoverer
+}
+
+// discoverersProcessor records discoverers set by relayer
+type discoverersProcessor struct {
+	writtenProcessor
+	discoverers map[uint8]DecimalsDiscoverer
+}
+
+func (p *discoverersProcessor) SetDecimalsDiscoverers(discoverers map[uint8]DecimalsDiscoverer) {
+	p.discoverers = discoverers
+}
+
+func TestRelayerSetsDecimalsDiscoverers(t *testing.T) {
+	discovering := &discoveringChain{mockChain: mockChain{id: 1}}
+	p := &discoverersProcessor{}
+	r := NewRelayer([]RelayedChain{discovering, &mockChain{id: 2}}, &mockMessageStore{statuses: make(map[uint64]MessageStatus)}, p)
+	r.Start(context.Background(), make(chan error, 1))
+
+	if len(p.discoverers) != 1 || p.discoverers[1] != discovering {
+		t.Fatalf("expected discoverer of chain 1 to be set, got %v", p.discoverers)
+	}
+}
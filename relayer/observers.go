@@ -0,0 +1,64 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"fmt"
+
+	"github.com/StirNetwork/chainbridge-core/metrics"
+	"github.com/rs/zerolog/log"
+)
+
+// MessageObserver records routed message, e.g. in metrics. Observer errors are logged and never stop message routing
+type MessageObserver func(m *Message) error
+
+// TransferCountObserver counts transfers by transfer type
+func TransferCountObserver(chainMetrics *metrics.ChainMetrics) MessageObserver {
+	return func(m *Message) error {
+		chainMetrics.NumberOfTransfers.WithLabelValues(string(m.Type)).Inc()
+		return nil
+	}
+}
+
+// FungibleTransferAmountObserver adds exact transferred amount of fungible transfer to total of its resource ID
+func FungibleTransferAmountObserver(chainMetrics *metrics.ChainMetrics) MessageObserver {
+	return func(m *Message) error {
+		payload, err := m.FungibleTransferPayload()
+		if err != nil {
+			return err
+		}
+		chainMetrics.AmountTransferred.Add(fmt.Sprintf("%x", m.ResourceId), payload.Amount)
+		return nil
+	}
+}
+
+// RegisterMessageObserver adds observers of messages with transfer type t
+func (r *Relayer) RegisterMessageObserver(t TransferType, observers ...MessageObserver) {
+	if r.observers == nil {
+		r.observers = make(map[TransferType][]MessageObserver)
+	}
+	r.observers[t] = append(r.observers[t], observers...)
+}
+
+func (r *Relayer) registerMetricsObservers(chainMetrics *metrics.ChainMetrics) {
+	r.RegisterMessageObserver(FungibleTransfer, TransferCountObserver(chainMetrics), FungibleTransferAmountObserver(chainMetrics))
+	r.RegisterMessageObserver(NonFungibleTransfer, TransferCountObserver(chainMetrics))
+	r.RegisterMessageObserver(GenericTransfer, TransferCountObserver(chainMetrics))
+}
+
+// observe passes message to observers of its transfer type. Failing or panicking observer does not affect routing
+func (r *Relayer) observe(m *Message) {
+	for _, o := range r.observers[m.Type] {
+		func() {
+			defer func() {
+				if rec := recover(); rec != nil {
+					log.Error().Msgf("message observer panicked on message %+v: %v", m, rec)
+				}
+			}()
+			if err := o(m); err != nil {
+				log.Warn().Err(err).Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Msg("Message observer failed")
+			}
+		}()
+	}
+}
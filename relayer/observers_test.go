@@ -0,0 +1,80 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestFungibleTransferAmountObserver tests that transferred amounts are accumulated without loss of precision
+func TestFungibleTransferAmountObserver(t *testing.T) {
+	chainMetrics := newTestChainMetrics()
+	observer := FungibleTransferAmountObserver(chainMetrics)
+	amount, _ := new(big.Int).SetString("145556700000000000001", 10) // not representable as float64
+	msg := &Message{
+		ResourceId: [32]byte{1},
+		Type:       FungibleTransfer,
+		Payload:    &FungibleTransferPayload{Amount: amount, Recipient: []byte{1}},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := observer(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := new(big.Int).Mul(amount, big.NewInt(2))
+	if total := chainMetrics.AmountTransferred.Total(fmt.Sprintf("%x", msg.ResourceId)); total.Cmp(expected) != 0 {
+		t.Fatalf("expected total %s, got %s", expected, total)
+	}
+}
+
+// TestRouteObservesMessagesOfAllTypes tests that messages without amount are counted and routed
+func TestRouteObservesMessagesOfAllTypes(t *testing.T) {
+	MessageRetryInterval = time.Millisecond
+	dest := &mockChain{id: 2}
+	r := NewRelayer([]RelayedChain{dest}, &mockMessageStore{statuses: make(map[uint64]MessageStatus)})
+	r.addRelayedChain(dest)
+	chainMetrics := newTestChainMetrics()
+	r.registerMetricsObservers(chainMetrics)
+
+	msgs := []*Message{
+		{Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{}},
+		{Destination: 2, DepositNonce: 2, Type: NonFungibleTransfer, Payload: &NonFungibleTransferPayload{TokenID: big.NewInt(1), Recipient: []byte{2}}},
+		{Destination: 2, DepositNonce: 3, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(1), Recipient: []byte{2}}},
+	}
+	for _, m := range msgs {
+		r.route(context.Background(), m, true)
+	}
+	if dest.writes != len(msgs) {
+		t.Fatalf("expected %d writes, got %d", len(msgs), dest.writes)
+	}
+	for _, transferType := range []TransferType{GenericTransfer, NonFungibleTransfer, FungibleTransfer} {
+		if count := testutil.ToFloat64(chainMetrics.NumberOfTransfers.WithLabelValues(string(transferType))); count != 1 {
+			t.Fatalf("expected 1 %s transfer, got %v", transferType, count)
+		}
+	}
+}
+
+func TestRouteIgnoresPanickingObserver(t *testing.T) {
+	dest := &mockChain{id: 2}
+	r := NewRelayer([]RelayedChain{dest}, &mockMessageStore{statuses: make(map[uint64]MessageStatus)})
+	r.addRelayedChain(dest)
+	r.RegisterMessageObserver(GenericTransfer, func(m *Message) error {
+		panic("observer failed")
+	}, func(m *Message) error {
+		return errors.New("observer failed")
+	})
+
+	r.route(context.Background(), &Message{Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{}}, true)
+	if dest.writes != 1 {
+		t.Fatalf("expected message to be written, got %d writes", dest.writes)
+	}
+}
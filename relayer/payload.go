@@ -0,0 +1,192 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+)
+
+// Payload is data of message specific to its transfer type. Payload is shared by copies of message and must not be
+// modified, processors changing it set a new payload of the message instead.
+type Payload interface {
+	// Elements returns payload in positional form it has in deposit events, used to encode and compare messages
+	Elements() [][]byte
+	// Validate checks that payload has all data its transfer type needs
+	Validate() error
+}
+
+// FungibleTransferPayload is payload of FungibleTransfer message
+type FungibleTransferPayload struct {
+	Amount    *big.Int
+	Recipient []byte
+}
+
+func (p *FungibleTransferPayload) Elements() [][]byte {
+	return [][]byte{intBytes(p.Amount), p.Recipient}
+}
+
+func (p *FungibleTransferPayload) Validate() error {
+	if p.Amount == nil || p.Amount.Sign() < 0 {
+		return fmt.Errorf("invalid amount %v", p.Amount)
+	}
+	if len(p.Recipient) == 0 {
+		return errors.New("empty recipient")
+	}
+	return nil
+}
+
+// NonFungibleTransferPayload is payload of NonFungibleTransfer message
+type NonFungibleTransferPayload struct {
+	TokenID   *big.Int
+	Recipient []byte
+	Metadata  []byte
+}
+
+func (p *NonFungibleTransferPayload) Elements() [][]byte {
+	return [][]byte{intBytes(p.TokenID), p.Recipient, p.Metadata}
+}
+
+func (p *NonFungibleTransferPayload) Validate() error {
+	if p.TokenID == nil || p.TokenID.Sign() < 0 {
+		return fmt.Errorf("invalid token ID %v", p.TokenID)
+	}
+	if len(p.Recipient) == 0 {
+		return errors.New("empty recipient")
+	}
+	return nil
+}
+
+// GenericTransferPayload is payload of GenericTransfer message
+type GenericTransferPayload struct {
+	Metadata []byte
+}
+
+func (p *GenericTransferPayload) Elements() [][]byte {
+	return [][]byte{p.Metadata}
+}
+
+func (p *GenericTransferPayload) Validate() error {
+	return nil
+}
+
+// FungibleTransferPayload returns valid payload of fungible transfer message
+func (m *Message) FungibleTransferPayload() (*FungibleTransferPayload, error) {
+	payload, ok := m.Payload.(*FungibleTransferPayload)
+	if !ok {
+		return nil, fmt.Errorf("wrong payload type, expected fungible transfer payload got %T", m.Payload)
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("malformed fungible transfer payload: %w", err)
+	}
+	return payload, nil
+}
+
+// NonFungibleTransferPayload returns valid payload of non fungible transfer message
+func (m *Message) NonFungibleTransferPayload() (*NonFungibleTransferPayload, error) {
+	payload, ok := m.Payload.(*NonFungibleTransferPayload)
+	if !ok {
+		return nil, fmt.Errorf("wrong payload type, expected non fungible transfer payload got %T", m.Payload)
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("malformed non fungible transfer payload: %w", err)
+	}
+	return payload, nil
+}
+
+// GenericTransferPayload returns valid payload of generic transfer message
+func (m *Message) GenericTransferPayload() (*GenericTransferPayload, error) {
+	payload, ok := m.Payload.(*GenericTransferPayload)
+	if !ok {
+		return nil, fmt.Errorf("wrong payload type, expected generic transfer payload got %T", m.Payload)
+	}
+	return payload, nil
+}
+
+// intBytes returns big endian bytes of x, nil x of malformed payload has no bytes
+func intBytes(x *big.Int) []byte {
+	if x == nil {
+		return nil
+	}
+	return x.Bytes()
+}
+
+var ErrUnregisteredPayloadType = errors.New("no payload type registered for transfer type")
+
+// PayloadDecoder builds payload of a transfer type from its positional form
+type PayloadDecoder func(elements [][]byte) (Payload, error)
+
+var (
+	payloadDecodersLock sync.RWMutex
+	payloadDecoders     = map[TransferType]PayloadDecoder{
+		FungibleTransfer: func(elements [][]byte) (Payload, error) {
+			if len(elements) != 2 {
+				return nil, fmt.Errorf("malformed payload. Len of payload should be 2, got %d", len(elements))
+			}
+			return &FungibleTransferPayload{Amount: new(big.Int).SetBytes(elements[0]), Recipient: elements[1]}, nil
+		},
+		NonFungibleTransfer: func(elements [][]byte) (Payload, error) {
+			if len(elements) != 3 {
+				return nil, fmt.Errorf("malformed payload. Len of payload should be 3, got %d", len(elements))
+			}
+			return &NonFungibleTransferPayload{TokenID: new(big.Int).SetBytes(elements[0]), Recipient: elements[1], Metadata: elements[2]}, nil
+		},
+		GenericTransfer: func(elements [][]byte) (Payload, error) {
+			if len(elements) != 1 {
+				return nil, fmt.Errorf("malformed payload. Len of payload should be 1, got %d", len(elements))
+			}
+			return &GenericTransferPayload{Metadata: elements[0]}, nil
+		},
+	}
+)
+
+// RegisterPayloadType registers decoder of custom transfer type payload, decoder of already registered type is replaced
+func RegisterPayloadType(t TransferType, decoder PayloadDecoder) {
+	payloadDecodersLock.Lock()
+	defer payloadDecodersLock.Unlock()
+	payloadDecoders[t] = decoder
+}
+
+// DecodePayload builds payload of transfer type t from its positional form with decoder registered for t
+func DecodePayload(t TransferType, elements [][]byte) (Payload, error) {
+	payloadDecodersLock.RLock()
+	decoder, ok := payloadDecoders[t]
+	payloadDecodersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnregisteredPayloadType, t)
+	}
+	return decoder(elements)
+}
+
+// ValidatePayload checks that message payload is valid and has the type registered for message transfer type
+func (m *Message) ValidatePayload() error {
+	if m.Payload == nil {
+		return errors.New("message has no payload")
+	}
+	_, err := m.payloadElements()
+	if err != nil {
+		return err
+	}
+	return m.Payload.Validate()
+}
+
+// payloadElements returns elements of message payload. Fails if decoder registered for message transfer type does not
+// decode them back to payload of the same type, as message could not be decoded after it is encoded then.
+func (m *Message) payloadElements() ([][]byte, error) {
+	if m.Payload == nil {
+		return nil, nil
+	}
+	elements := m.Payload.Elements()
+	decoded, err := DecodePayload(m.Type, elements)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.TypeOf(decoded) != reflect.TypeOf(m.Payload) {
+		return nil, fmt.Errorf("wrong payload type %T of transfer type %s, expected %T", m.Payload, m.Type, decoded)
+	}
+	return elements, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+const customTransfer TransferType = "CustomTransfer"
+
+// customPayload is payload of custom transfer type registered by registerCustomPayload
+type customPayload struct {
+	Data [][]byte
+}
+
+func (p *customPayload) Elements() [][]byte {
+	return p.Data
+}
+
+func (p *customPayload) Validate() error {
+	if len(p.Data) == 0 {
+		return errors.New("empty custom payload")
+	}
+	return nil
+}
+
+func registerCustomPayload() {
+	RegisterPayloadType(customTransfer, func(elements [][]byte) (Payload, error) {
+		return &customPayload{Data: elements}, nil
+	})
+}
+
+func TestFungibleTransferPayload(t *testing.T) {
+	m := &Message{
+		Type:    FungibleTransfer,
+		Payload: &FungibleTransferPayload{Amount: big.NewInt(10), Recipient: []byte{1, 2}},
+	}
+
+	payload, err := m.FungibleTransferPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.Amount.Int64() != 10 || !bytes.Equal(payload.Recipient, []byte{1, 2}) {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+	if err = m.ValidatePayload(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNonFungibleTransferPayload(t *testing.T) {
+	m := &Message{
+		Type:    NonFungibleTransfer,
+		Payload: &NonFungibleTransferPayload{TokenID: big.NewInt(42), Recipient: []byte{1}, Metadata: []byte{2}},
+	}
+
+	payload, err := m.NonFungibleTransferPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.TokenID.Int64() != 42 || !bytes.Equal(payload.Recipient, []byte{1}) || !bytes.Equal(payload.Metadata, []byte{2}) {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+	if err = m.ValidatePayload(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenericTransferPayload(t *testing.T) {
+	m := &Message{
+		Type:    GenericTransfer,
+		Payload: &GenericTransferPayload{Metadata: []byte("metadata")},
+	}
+
+	payload, err := m.GenericTransferPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload.Metadata, []byte("metadata")) {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+	if err = m.ValidatePayload(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPayloadAccessorsRejectMalformedPayload(t *testing.T) {
+	m := &Message{Type: FungibleTransfer, Payload: &FungibleTransferPayload{Recipient: []byte{1}}}
+	if _, err := m.FungibleTransferPayload(); err == nil {
+		t.Fatal("expected error for missing amount")
+	}
+	m = &Message{Type: NonFungibleTransfer, Payload: &GenericTransferPayload{}}
+	if _, err := m.NonFungibleTransferPayload(); err == nil {
+		t.Fatal("expected error for wrong payload type")
+	}
+	if _, err := (&Message{Type: GenericTransfer}).GenericTransferPayload(); err == nil {
+		t.Fatal("expected error for missing payload")
+	}
+}
+
+func TestValidatePayloadMalformed(t *testing.T) {
+	testCases := []struct {
+		name string
+		msg  *Message
+	}{
+		{"fungible missing recipient", &Message{Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(1)}}},
+		{"fungible negative amount", &Message{Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(-1), Recipient: []byte{1}}}},
+		{"non fungible missing token ID", &Message{Type: NonFungibleTransfer, Payload: &NonFungibleTransferPayload{Recipient: []byte{1}}}},
+		{"payload of other transfer type", &Message{Type: FungibleTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}},
+		{"generic missing payload", &Message{Type: GenericTransfer}},
+		{"unknown transfer type", &Message{Type: "Unknown", Payload: &GenericTransferPayload{Metadata: []byte{1}}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.msg.ValidatePayload(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestRegisterPayloadType(t *testing.T) {
+	registerCustomPayload()
+
+	if err := (&Message{Type: customTransfer, Payload: &customPayload{Data: [][]byte{{1}}}}).ValidatePayload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Message{Type: customTransfer, Payload: &customPayload{}}).ValidatePayload(); err == nil {
+		t.Fatal("expected custom payload validation error")
+	}
+
+	p, err := DecodePayload(customTransfer, [][]byte{{1}, {2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if custom, ok := p.(*customPayload); !ok || len(custom.Data) != 2 {
+		t.Fatalf("unexpected payload %+v", p)
+	}
+	if _, err = DecodePayload("Unknown", [][]byte{{1}}); err == nil {
+		t.Fatal("expected error for unregistered transfer type")
+	}
+}
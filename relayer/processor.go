@@ -0,0 +1,164 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProcessorRetryInterval is how long message waits before it is processed again if processor did not set RetryAfter
+var ProcessorRetryInterval = time.Second * 30
+
+// ProcessorOutcome tells relayer what to do with processed message
+type ProcessorOutcome uint8
+
+const (
+	OutcomeContinue   ProcessorOutcome = iota // Message is passed unchanged to the next processor
+	OutcomeModified                           // Message was changed and is passed to the next processor
+	OutcomeSkip                               // Message is not relayed, it is stored as skipped with reason
+	OutcomeRetryLater                         // Message is processed again after RetryAfter, it stays pending meanwhile
+	OutcomeQuarantine                         // Message is not relayed until it is approved, it is stored as quarantined with reason
+)
+
+var outcomeNames = map[ProcessorOutcome]string{OutcomeContinue: "continue", OutcomeModified: "modified", OutcomeSkip: "skip", OutcomeRetryLater: "retry later", OutcomeQuarantine: "quarantine"}
+
+func (o ProcessorOutcome) String() string {
+	if name, ok := outcomeNames[o]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown outcome %d", o)
+}
+
+type ProcessorResult struct {
+	Outcome ProcessorOutcome
+	// Reason why message is skipped, retried later or quarantined
+	Reason string
+	// RetryAfter is how long to wait before retrying message, ProcessorRetryInterval is used if it is not set
+	RetryAfter time.Duration
+}
+
+func Continue() ProcessorResult {
+	return ProcessorResult{Outcome: OutcomeContinue}
+}
+
+func Modified() ProcessorResult {
+	return ProcessorResult{Outcome: OutcomeModified}
+}
+
+func Skip(reason string) ProcessorResult {
+	return ProcessorResult{Outcome: OutcomeSkip, Reason: reason}
+}
+
+func RetryLater(reason string, after time.Duration) ProcessorResult {
+	return ProcessorResult{Outcome: OutcomeRetryLater, Reason: reason, RetryAfter: after}
+}
+
+func Quarantine(reason string) ProcessorResult {
+	return ProcessorResult{Outcome: OutcomeQuarantine, Reason: reason}
+}
+
+// MessageProcessor inspects or changes message before it is written to destination chain. Processor must not
+// change source, destination or deposit nonce of message. Error means message could not be processed, it then
+// stays pending and is requeued with backoff.
+type MessageProcessor interface {
+	Name() string
+	Process(m *Message) (ProcessorResult, error)
+}
+
+// WriteObserver is implemented by processors that need to know which of the messages they processed were written
+// to destination chain. Observer errors are logged and do not change status of written message.
+type WriteObserver interface {
+	MessageWritten(m *Message) error
+	// MessageNotWritten is called when processed message was skipped, quarantined, failed or left pending on stop.
+	// Message may be processed again later.
+	MessageNotWritten(m *Message)
+}
+
+type processorFunc struct {
+	name    string
+	process func(m *Message) (ProcessorResult, error)
+}
+
+func (p *processorFunc) Name() string {
+	return p.name
+}
+
+func (p *processorFunc) Process(m *Message) (ProcessorResult, error) {
+	return p.process(m)
+}
+
+// NewProcessorFunc creates MessageProcessor named name from function
+func NewProcessorFunc(name string, process func(m *Message) (ProcessorResult, error)) MessageProcessor {
+	return &processorFunc{name: name, process: process}
+}
+
+// Pipeline runs processors in order until one of them skips message, asks to retry it later, quarantines it or fails
+type Pipeline []MessageProcessor
+
+// Process returns OutcomeModified if any processor changed the message. Reason of skip, retry and quarantine is prefixed with processor name.
+func (p Pipeline) Process(m *Message) (ProcessorResult, error) {
+	return p.process(m, "")
+}
+
+// ProcessApproved processes message approved after quarantine with reason. Quarantine of the processor named in
+// reason prefix is passed over, other processors can still quarantine message.
+func (p Pipeline) ProcessApproved(m *Message, reason string) (ProcessorResult, error) {
+	return p.process(m, reason)
+}
+
+// process passes over quarantine of processor that quarantined approved message with approvedReason
+func (p Pipeline) process(m *Message, approvedReason string) (ProcessorResult, error) {
+	result := Continue()
+	for _, mp := range p {
+		r, err := mp.Process(m)
+		if err != nil {
+			return ProcessorResult{}, fmt.Errorf("processor %s: %w", mp.Name(), err)
+		}
+		switch r.Outcome {
+		case OutcomeContinue:
+		case OutcomeModified:
+			result = Modified()
+		case OutcomeQuarantine:
+			if approvedReason != "" && strings.HasPrefix(approvedReason, mp.Name()+": ") {
+				continue
+			}
+			r.Reason = fmt.Sprintf("%s: %s", mp.Name(), r.Reason)
+			return r, nil
+		case OutcomeSkip, OutcomeRetryLater:
+			r.Reason = fmt.Sprintf("%s: %s", mp.Name(), r.Reason)
+			return r, nil
+		default:
+			return ProcessorResult{}, fmt.Errorf("processor %s: %s", mp.Name(), r.Outcome)
+		}
+	}
+	return result, nil
+}
+
+// ProcessorFactory builds processor from parameters of its chain config entry
+type ProcessorFactory func(params map[string]interface{}) (MessageProcessor, error)
+
+var processorFactories = map[string]ProcessorFactory{
+	AdjustDecimalsProcessorType: NewAdjustDecimalsProcessorFromConfig,
+	AccessListsProcessorType:    NewAccessListsProcessorFromConfig,
+}
+
+// RegisterProcessorFactory makes processors of processorType configurable from chain config
+func RegisterProcessorFactory(processorType string, factory ProcessorFactory) {
+	processorFactories[processorType] = factory
+}
+
+// NewMessageProcessor builds processor of processorType configured in chain config
+func NewMessageProcessor(processorType string, params map[string]interface{}) (MessageProcessor, error) {
+	factory, ok := processorFactories[processorType]
+	if !ok {
+		return nil, fmt.Errorf("unknown message processor type %s", processorType)
+	}
+	p, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure %s processor: %w", processorType, err)
+	}
+	return p, nil
+}
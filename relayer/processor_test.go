@@ -0,0 +1,239 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// appendingProcessor appends byte to generic message metadata and records metadata length it got
+type appendingProcessor struct {
+	lengths []int
+}
+
+func (p *appendingProcessor) Name() string {
+	return "append"
+}
+
+func (p *appendingProcessor) Process(m *Message) (ProcessorResult, error) {
+	var metadata []byte
+	if payload, err := m.GenericTransferPayload(); err == nil {
+		metadata = payload.Metadata
+	}
+	p.lengths = append(p.lengths, len(metadata))
+	m.Payload = &GenericTransferPayload{Metadata: append(append([]byte{}, metadata...), 0)}
+	return Modified(), nil
+}
+
+// writtenProcessor records messages it is told were written or not written
+type writtenProcessor struct {
+	written    []*Message
+	notWritten []*Message
+}
+
+func (p *writtenProcessor) Name() string {
+	return "written"
+}
+
+func (p *writtenProcessor) Process(m *Message) (ProcessorResult, error) {
+	return Continue(), nil
+}
+
+func (p *writtenProcessor) MessageWritten(m *Message) error {
+	p.written = append(p.written, m)
+	return nil
+}
+
+func (p *writtenProcessor) MessageNotWritten(m *Message) {
+	p.notWritten = append(p.notWritten, m)
+}
+
+func TestPipeline(t *testing.T) {
+	appending := &appendingProcessor{}
+	called := false
+	last := NewProcessorFunc("last", func(m *Message) (ProcessorResult, error) {
+		called = true
+		return Continue(), nil
+	})
+
+	result, err := Pipeline{appending, last}.Process(&Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeModified || !called {
+		t.Fatalf("unexpected outcome %s, last processor called: %t", result.Outcome, called)
+	}
+
+	called = false
+	skipping := NewProcessorFunc("skipping", func(m *Message) (ProcessorResult, error) {
+		return Skip("not allowed"), nil
+	})
+	result, err = Pipeline{appending, skipping, last}.Process(&Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeSkip || result.Reason != "skipping: not allowed" || called {
+		t.Fatalf("unexpected result %+v, last processor called: %t", result, called)
+	}
+
+	failing := NewProcessorFunc("failing", func(m *Message) (ProcessorResult, error) {
+		return ProcessorResult{}, errors.New("failed")
+	})
+	_, err = Pipeline{failing, last}.Process(&Message{})
+	if err == nil || called {
+		t.Fatalf("expected processor error, last processor called: %t", called)
+	}
+}
+
+func TestPipelineProcessApprovedPassesOverQuarantiningProcessorOnly(t *testing.T) {
+	limits := NewProcessorFunc("limits", func(m *Message) (ProcessorResult, error) {
+		return Quarantine("over limit"), nil
+	})
+	review := NewProcessorFunc("review", func(m *Message) (ProcessorResult, error) {
+		return Quarantine("manual review"), nil
+	})
+
+	result, err := Pipeline{limits}.ProcessApproved(&Message{}, "limits: over limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeContinue {
+		t.Fatalf("expected quarantine of approving processor to be passed over, got %+v", result)
+	}
+
+	result, err = Pipeline{limits, review}.ProcessApproved(&Message{}, "limits: over limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != OutcomeQuarantine || result.Reason != "review: manual review" {
+		t.Fatalf("expected other processor to quarantine approved message, got %+v", result)
+	}
+}
+
+func TestRouteStoresSkippedMessage(t *testing.T) {
+	dest := &mockChain{id: 2}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	r := NewRelayer([]RelayedChain{dest}, store, NewProcessorFunc("deny", func(m *Message) (ProcessorResult, error) {
+		return Skip("denied recipient"), nil
+	}))
+	r.addRelayedChain(dest)
+
+	r.route(context.Background(), &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}, true)
+	if dest.writes != 0 {
+		t.Fatalf("skipped message must not be written, got %d writes", dest.writes)
+	}
+	if store.statuses[1] != MessageStatusSkipped || store.reasons[1] != "deny: denied recipient" {
+		t.Fatalf("unexpected status %v and reason %q", store.statuses[1], store.reasons[1])
+	}
+}
+
+func TestRouteSkipsMalformedMessage(t *testing.T) {
+	dest := &mockChain{id: 2}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	appending := &appendingProcessor{}
+	r := NewRelayer([]RelayedChain{dest}, store, appending)
+	r.addRelayedChain(dest)
+
+	r.route(context.Background(), &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: FungibleTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}, true)
+	if dest.writes != 0 || len(appending.lengths) != 0 {
+		t.Fatalf("malformed message must not be processed or written, got %d writes", dest.writes)
+	}
+	if store.statuses[1] != MessageStatusSkipped || !strings.HasPrefix(store.reasons[1], "malformed payload: ") {
+		t.Fatalf("unexpected status %v and reason %q", store.statuses[1], store.reasons[1])
+	}
+}
+
+func TestRouteRetriesProcessingLater(t *testing.T) {
+	dest := &mockChain{id: 2}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	appending := &appendingProcessor{}
+	attempts := 0
+	r := NewRelayer([]RelayedChain{dest}, store, appending, NewProcessorFunc("limit", func(m *Message) (ProcessorResult, error) {
+		attempts++
+		if attempts < 3 {
+			return RetryLater("limit reached", time.Millisecond), nil
+		}
+		return Continue(), nil
+	}))
+	r.addRelayedChain(dest)
+
+	r.route(context.Background(), &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}, true)
+	if dest.writes != 1 || store.statuses[1] != MessageStatusDone {
+		t.Fatalf("expected message to be written once and done, got %d writes and status %v", dest.writes, store.statuses[1])
+	}
+	// Every attempt processes the original message
+	for _, l := range appending.lengths {
+		if l != 1 {
+			t.Fatalf("expected every attempt to get original payload, got lengths %v", appending.lengths)
+		}
+	}
+}
+
+func TestRouteAbandonsRetryOnStop(t *testing.T) {
+	dest := &mockChain{id: 2}
+	store := &mockMessageStore{statuses: map[uint64]MessageStatus{1: MessageStatusPending}}
+	r := NewRelayer([]RelayedChain{dest}, store, NewProcessorFunc("limit", func(m *Message) (ProcessorResult, error) {
+		return RetryLater("limit reached", time.Hour), nil
+	}))
+	r.addRelayedChain(dest)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.route(ctx, &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}, true)
+	if dest.writes != 0 || store.statuses[1] != MessageStatusPending {
+		t.Fatalf("expected message to stay pending, got %d writes and status %v", dest.writes, store.statuses[1])
+	}
+}
+
+func TestRouteQuarantinesMessageUntilApproved(t *testing.T) {
+	dest := &mockChain{id: 2}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	r := NewRelayer([]RelayedChain{dest}, store, NewProcessorFunc("limits", func(m *Message) (ProcessorResult, error) {
+		return Quarantine("over limit"), nil
+	}), &appendingProcessor{})
+	r.addRelayedChain(dest)
+
+	m := &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}
+	r.route(context.Background(), m, true)
+	if dest.writes != 0 || store.statuses[1] != MessageStatusQuarantined || store.reasons[1] != "limits: over limit" {
+		t.Fatalf("expected message to be quarantined, got %d writes, status %v and reason %q", dest.writes, store.statuses[1], store.reasons[1])
+	}
+
+	store.statuses[1] = MessageStatusApproved
+	r.route(context.Background(), m, true)
+	if dest.writes != 1 || store.statuses[1] != MessageStatusDone {
+		t.Fatalf("expected approved message to be written, got %d writes and status %v", dest.writes, store.statuses[1])
+	}
+}
+
+func TestRouteNotifiesProcessorsOfWrittenMessage(t *testing.T) {
+	dest := &mockChain{id: 2, failures: 1}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	written := &writtenProcessor{}
+	r := NewRelayer([]RelayedChain{dest}, store, &appendingProcessor{}, written)
+	r.addRelayedChain(dest)
+
+	m := &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}
+	defer func(limit int) { MessageRetryLimit = limit }(MessageRetryLimit)
+	MessageRetryLimit = 0
+	r.route(context.Background(), m, true)
+	if len(written.written) != 0 || len(written.notWritten) != 1 {
+		t.Fatalf("expected message that failed to be written to be observed as not written, got %d written and %d not written", len(written.written), len(written.notWritten))
+	}
+	r.route(context.Background(), m, false)
+	if len(written.written) != 1 || len(written.written[0].Payload.Elements()[0]) != 2 {
+		t.Fatalf("expected processed message to be observed once, got %+v", written.written)
+	}
+}
+
+func TestNewMessageProcessorUnknownType(t *testing.T) {
+	_, err := NewMessageProcessor("unknown", nil)
+	if err == nil {
+		t.Fatal("expected error for unknown processor type")
+	}
+}
@@ -4,102 +4,379 @@
 package relayer
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/StirNetwork/chainbridge-core/metrics"
-	"github.com/gorilla/mux"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 )
 
-type MessageProcessor func(message *Message) error
+var MessageRetryLimit = 5
+var MessageRetryInterval = time.Second * 5
+
+// MessageRequeueMaxInterval caps backoff of messages requeued after their writes or processing failed
+var MessageRequeueMaxInterval = time.Minute * 10
+
+var ErrMessageNotFound = errors.New("message not found")
+
+// MessageStore persists messages between reading them from source chain and writing them to destination
+// so they are not lost if writing fails or relayer restarts
+type MessageStore interface {
+	StoreMessage(m *Message, status MessageStatus) error
+	// StoreMessageWithReason stores message with the reason processor skipped or quarantined it
+	StoreMessageWithReason(m *Message, status MessageStatus, reason string) error
+	// GetMessageStatus returns ErrMessageNotFound if message was never stored
+	GetMessageStatus(m *Message) (MessageStatus, error)
+	// GetMessage returns stored message with the same source, destination and deposit nonce as m, and its status.
+	// Returns ErrMessageNotFound if message was never stored.
+	GetMessage(m *Message) (*Message, MessageStatus, error)
+	// GetMessageReason returns the reason processor skipped or quarantined stored message, which is kept after
+	// quarantined message is approved. Returns ErrMessageNotFound if message was never stored.
+	GetMessageReason(m *Message) (string, error)
+	PendingMessages() ([]*Message, error)
+}
 
 type RelayedChain interface {
-	PollEvents(stop <-chan struct{}, sysErr chan<- error, eventsChan chan *Message)
-	Write(message *Message) error
+	// PollEvents sends messages read from chain to eventsChan until ctx is done. It returns once
+	// chain listener stopped and all messages it already read are sent.
+	PollEvents(ctx context.Context, sysErr chan<- error, eventsChan chan *Message)
+	// Write writes message to chain, it gives up and returns ctx error once ctx is done
+	Write(ctx context.Context, message *Message) error
 	ChainID() uint8
 }
 
-func NewRelayer(chains []RelayedChain, messageProcessors ...MessageProcessor) *Relayer {
-	return &Relayer{relayedChains: chains, messageProcessors: messageProcessors}
+func NewRelayer(chains []RelayedChain, messageStore MessageStore, messageProcessors ...MessageProcessor) *Relayer {
+	writeCtx, cancelWrites := context.WithCancel(context.Background())
+	return &Relayer{relayedChains: chains, messageStore: messageStore, messageProcessors: messageProcessors, bridgeMetrics: metrics.NewBridgeMetrics(), done: make(chan struct{}), writeCtx: writeCtx, cancelWrites: cancelWrites}
 }
 
 type Relayer struct {
 	relayedChains     []RelayedChain
 	registry          map[uint8]RelayedChain
+	messageStore      MessageStore
 	messageProcessors []MessageProcessor
+	observers         map[TransferType][]MessageObserver
+	bridgeMetrics     *metrics.BridgeMetrics
+
+	pipelines map[uint8]Pipeline    // processors per destination chain
+	pools     map[uint8]*workerPool // worker pools per destination chain
+	routes    sync.WaitGroup        // running workers
+	done      chan struct{}         // closed when Start returns
+
+	writeCtx     context.Context // passed to writes, outlives Start so messages being written are finished on stop
+	cancelWrites context.CancelFunc
 }
 
 // Starts the relayer. Relayer routine is starting all the chains
-// and passing them with a channel that accepts unified cross chain message format
-func (r *Relayer) Start(stop <-chan struct{}, sysErr chan error) {
+// and passing them with a channel that accepts unified cross chain message format.
+// Once ctx is done chains are stopped and messages they still read are stored as pending, without routing.
+func (r *Relayer) Start(ctx context.Context, sysErr chan error) {
+	defer close(r.done)
 	log.Debug().Msgf("Starting relayer")
 	messagesChannel := make(chan *Message)
 
 	// init new instance of ChainMetrics
 	chainMetrics := metrics.NewChainMetrics()
+	r.registerMetricsObservers(chainMetrics)
 
-	// init new mux router
-	router := mux.NewRouter()
-
-	// register path + handler
-	router.Path("/metrics").Handler(promhttp.Handler())
+	pending, err := r.messageStore.PendingMessages()
+	if err != nil {
+		sysErr <- fmt.Errorf("error %w on loading pending messages", err)
+		return
+	}
 
-	// start http server in non-blocking goroutine
-	go func() {
-		log.Fatal().Err(http.ListenAndServe(":2112", router))
-	}()
-	log.Debug().Msg("listening on: http://localhost:2112/metrics")
+	discoverers := make(map[uint8]DecimalsDiscoverer)
+	for _, c := range r.relayedChains {
+		if d, ok := c.(DecimalsDiscoverer); ok {
+			discoverers[c.ChainID()] = d
+		}
+	}
 
+	r.pipelines = make(map[uint8]Pipeline)
+	r.pools = make(map[uint8]*workerPool)
+	chains := sync.WaitGroup{}
 	for _, c := range r.relayedChains {
 		log.Debug().Msgf("Starting chain %v", c.ChainID())
 		r.addRelayedChain(c)
-		go c.PollEvents(stop, sysErr, messagesChannel)
+		r.pipelines[c.ChainID()] = pipeline(r.messageProcessors, c)
+		setDecimalsDiscoverers(r.pipelines[c.ChainID()], discoverers)
+		r.pools[c.ChainID()] = r.startWorkers(ctx, c)
+		chains.Add(1)
+		go func(c RelayedChain) {
+			defer chains.Done()
+			c.PollEvents(ctx, sysErr, messagesChannel)
+		}(c)
+	}
+	chainsStopped := make(chan struct{})
+	go func() {
+		chains.Wait()
+		close(chainsStopped)
+	}()
+
+	// Messages left pending by previous run are replayed before new ones, in deposit nonce order
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Source != pending[j].Source {
+			return pending[i].Source < pending[j].Source
+		}
+		if pending[i].Destination != pending[j].Destination {
+			return pending[i].Destination < pending[j].Destination
+		}
+		return pending[i].DepositNonce < pending[j].DepositNonce
+	})
+	for _, m := range pending {
+		log.Info().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Msg("Replaying pending message")
+		r.dispatch(ctx, m)
 	}
+
 	for {
 		select {
 		case m := <-messagesChannel:
-			go r.route(m, chainMetrics)
+			if !r.persistMessage(m) {
+				continue
+			}
+			r.bridgeMetrics.DepositSeen(m.Source, m.Destination, m.ResourceId)
+			r.dispatch(ctx, m)
 			continue
-		case <-stop:
+		case <-chainsStopped:
+			log.Info().Msg("All chains stopped")
 			return
 		}
 	}
 }
 
+// Stop waits until relayer started with Start stops after its context is done and messages being written by workers are written.
+// If ctx is done first, writes in progress are cancelled and error is returned once workers return,
+// messages that were not written stay pending and are replayed on the next start.
+func (r *Relayer) Stop(ctx context.Context) error {
+	defer r.cancelWrites()
+	stopped := make(chan struct{})
+	go func() {
+		<-r.done
+		r.routes.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		r.cancelWrites()
+		<-stopped
+		return fmt.Errorf("relayer did not stop in time: %w", ctx.Err())
+	}
+}
+
+// dispatch passes message to workers of its destination chain without waiting for them,
+// so a slow destination does not hold up messages to others. Message stays pending if relayer is stopping.
+func (r *Relayer) dispatch(ctx context.Context, m *Message) {
+	pool, ok := r.pools[m.Destination]
+	if !ok {
+		// route reports unknown destination
+		r.route(ctx, m, true)
+		return
+	}
+	if ctx.Err() != nil {
+		log.Info().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Msg("Relayer is stopping, message is left pending")
+		return
+	}
+	pool.enqueue(m)
+}
+
 // Route function winds destination writer by mapping DestinationID from message to registered writer.
-func (r *Relayer) route(m *Message, chainMetrics *metrics.ChainMetrics) {
+// Observers are notified on the first attempt only. Returns true if processing failed or writes failed
+// MessageRetryLimit times, message then stays pending and should be requeued. Retries are abandoned
+// once ctx is done, message then stays pending until restart.
+func (r *Relayer) route(ctx context.Context, m *Message, firstAttempt bool) bool {
 	destChain, ok := r.registry[m.Destination]
 	if !ok {
 		log.Error().Msgf("no resolver for destID %v to send message registered", m.Destination)
-		return
+		r.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, "unknown_destination")
+		return false
+	}
+
+	if err := m.ValidatePayload(); err != nil {
+		reason := fmt.Sprintf("malformed payload: %s", err)
+		log.Warn().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Str("reason", reason).Msg("Message skipped")
+		r.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, "malformed")
+		if err := r.messageStore.StoreMessageWithReason(m, MessageStatusSkipped, reason); err != nil {
+			log.Error().Err(err).Msgf("marking message %+v as skipped", m)
+		}
+		return false
+	}
+
+	if firstAttempt {
+		r.observe(m)
 	}
 
-	// extract amount from Payload field
-	// TODO: if the message is not for ERC20 transfer that panics or errors could appear
-	payloadAmount, err := m.extractAmountTransferred()
+	processed, err := r.process(ctx, m)
 	if err != nil {
-		log.Error().Err(err)
-		return
+		log.Error().Err(err).Msgf("processing message %+v failed", m)
+		r.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, "processor")
+		r.notifyNotWritten(m)
+		return true
+	}
+	if processed == nil {
+		r.notifyNotWritten(m)
+		return false
 	}
 
-	// increment chain metrics
-	chainMetrics.AmountTransferred.Add(payloadAmount)
-	chainMetrics.NumberOfTransfers.Inc()
+	log.Debug().Msgf("Sending message %+v to destination %v", processed, m.Destination)
+	for i := 0; ; i++ {
+		err := destChain.Write(r.writeCtx, processed)
+		if err == nil {
+			break
+		}
+		if r.writeCtx.Err() != nil {
+			log.Warn().Err(err).Msgf("relayer stopped before message %+v was written, message stays pending until restart", m)
+			r.notifyNotWritten(processed)
+			return false
+		}
+		if i >= MessageRetryLimit {
+			log.Error().Err(err).Msgf("writing message %+v failed after %d retries", m, i)
+			r.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, "write")
+			r.notifyNotWritten(processed)
+			return true
+		}
+		backoff := MessageRetryInterval * time.Duration(1<<uint(i))
+		log.Warn().Err(err).Msgf("writing message %+v failed, retrying in %s", m, backoff)
+		select {
+		case <-ctx.Done():
+			log.Warn().Msgf("relayer is stopping, message %+v stays pending until restart", m)
+			r.notifyNotWritten(processed)
+			return false
+		case <-time.After(backoff):
+		}
+	}
 
-	for _, mp := range r.messageProcessors {
-		if err := mp(m); err != nil {
-			log.Error().Err(fmt.Errorf("error %w processing mesage %v", err, m))
-			return
+	if err := r.messageStore.StoreMessage(m, MessageStatusDone); err != nil {
+		log.Error().Err(err).Msgf("marking message %+v as done", m)
+	}
+	for _, mp := range r.processors(m.Destination) {
+		if wo, ok := mp.(WriteObserver); ok {
+			if err := wo.MessageWritten(processed); err != nil {
+				log.Error().Err(err).Msgf("processor %s failed to observe written message %+v", mp.Name(), m)
+			}
 		}
 	}
+	return false
+}
 
-	log.Debug().Msgf("Sending message %+v to destination %v", m, m.Destination)
-	if err := destChain.Write(m); err != nil {
-		log.Error().Err(err).Msgf("writing message %+v", m)
-		return
+// notifyNotWritten tells processors of message destination that message they processed was not written
+func (r *Relayer) notifyNotWritten(m *Message) {
+	for _, mp := range r.processors(m.Destination) {
+		if wo, ok := mp.(WriteObserver); ok {
+			wo.MessageNotWritten(m)
+		}
+	}
+}
+
+// process runs processors of message destination on a copy of m, original message is kept for replay.
+// Returns nil message if message should not be written, it is then either skipped, quarantined or left pending
+// because relayer is stopping. Returns error if a processor failed.
+func (r *Relayer) process(ctx context.Context, m *Message) (*Message, error) {
+	p := r.processors(m.Destination)
+	process := p.Process
+	if status, err := r.messageStore.GetMessageStatus(m); err == nil && status == MessageStatusApproved {
+		reason, err := r.messageStore.GetMessageReason(m)
+		if err != nil {
+			return nil, fmt.Errorf("reading quarantine reason of approved message: %w", err)
+		}
+		process = func(m *Message) (ProcessorResult, error) {
+			return p.ProcessApproved(m, reason)
+		}
 	}
+	for {
+		processed := m.copy()
+		result, err := process(processed)
+		if err != nil {
+			return nil, err
+		}
+		switch result.Outcome {
+		case OutcomeSkip:
+			log.Warn().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Str("reason", result.Reason).Msg("Message skipped")
+			r.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, "skipped")
+			if err := r.messageStore.StoreMessageWithReason(m, MessageStatusSkipped, result.Reason); err != nil {
+				log.Error().Err(err).Msgf("marking message %+v as skipped", m)
+			}
+			return nil, nil
+		case OutcomeQuarantine:
+			log.Warn().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Str("reason", result.Reason).Msg("Message quarantined until approved")
+			r.bridgeMetrics.Failure(m.Source, m.Destination, m.ResourceId, "quarantined")
+			if err := r.messageStore.StoreMessageWithReason(m, MessageStatusQuarantined, result.Reason); err != nil {
+				log.Error().Err(err).Msgf("marking message %+v as quarantined", m)
+			}
+			return nil, nil
+		case OutcomeRetryLater:
+			retryAfter := result.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = ProcessorRetryInterval
+			}
+			log.Info().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Str("reason", result.Reason).Msgf("Message processing retried in %s", retryAfter)
+			select {
+			case <-ctx.Done():
+				log.Warn().Msgf("relayer is stopping, message %+v stays pending until restart", m)
+				return nil, nil
+			case <-time.After(retryAfter):
+			}
+		default:
+			return processed, nil
+		}
+	}
+}
+
+// processors returns pipeline of messages to destination chain
+func (r *Relayer) processors(destination uint8) Pipeline {
+	if p, ok := r.pipelines[destination]; ok {
+		return p
+	}
+	return r.messageProcessors
+}
+
+// pipeline returns processors of messages to chain c, processors passed to relayer run first
+func pipeline(processors []MessageProcessor, c RelayedChain) Pipeline {
+	p := append(Pipeline{}, processors...)
+	if pc, ok := c.(ProcessorConfigurer); ok {
+		p = append(p, pc.MessageProcessors()...)
+	}
+	return p
+}
+
+// setDecimalsDiscoverers passes discoverers to processors that discover decimals
+func setDecimalsDiscoverers(processors []MessageProcessor, discoverers map[uint8]DecimalsDiscoverer) {
+	for _, mp := range processors {
+		if s, ok := mp.(DecimalsDiscoverersSetter); ok {
+			s.SetDecimalsDiscoverers(discoverers)
+		}
+	}
+}
+
+// persistMessage stores newly read message as pending. Returns false if message
+// was already seen, either relayed or waiting to be relayed. Stored message with the same deposit nonce but
+// different deposit comes from block orphaned by reorg, it is replaced by the newly read one.
+func (r *Relayer) persistMessage(m *Message) bool {
+	stored, status, err := r.messageStore.GetMessage(m)
+	if err == nil && (stored == nil || stored.sameDeposit(m)) {
+		log.Debug().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Msgf("Skipping already stored message with status %v", status)
+		return false
+	}
+	if err == nil {
+		log.Warn().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Msgf("Replacing stored message with status %v by different deposit with the same nonce, stored message was deposited in orphaned block", status)
+		r.bridgeMetrics.Failure(m.Source, m.Destination, stored.ResourceId, "orphaned")
+	} else if !errors.Is(err, ErrMessageNotFound) {
+		log.Error().Err(err).Msgf("reading status of message %+v", m)
+	}
+	if err := r.messageStore.StoreMessage(m, MessageStatusPending); err != nil {
+		log.Error().Err(err).Msgf("persisting message %+v", m)
+	}
+	return true
+}
+
+// ProcessorConfigurer is implemented by chains that configure processors of messages written to them
+type ProcessorConfigurer interface {
+	MessageProcessors() []MessageProcessor
 }
 
 func (r *Relayer) addRelayedChain(c RelayedChain) {
@@ -1,42 +1,340 @@
 package relayer
 
 import (
+	"context"
+	"errors"
 	"math/big"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/StirNetwork/chainbridge-core/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-// TestRouter tests relayers router
-func TestAdjustDecimalsForERC20AmountMessageProcessor(t *testing.T) {
-	a, _ := big.NewInt(0).SetString("145556700000000000000", 10) // 145.5567 tokens
-	msg := &Message{
-		Destination: 2,
-		Source:      1,
-		Payload: []interface{}{
-			a.Bytes(), // 145.5567 tokens
-		},
-	}
-	err := AdjustDecimalsForERC20AmountMessageProcessor(map[uint8]uint64{1: 18, 2: 2})(msg)
-	if err != nil {
-		t.Fatal()
+type mockChain struct {
+	id       uint8
+	failures int
+	writes   int
+}
+
+func (c *mockChain) PollEvents(ctx context.Context, sysErr chan<- error, eventsChan chan *Message) {}
+
+func (c *mockChain) Write(ctx context.Context, message *Message) error {
+	c.writes++
+	if c.writes <= c.failures {
+		return errors.New("write failed")
+	}
+	return nil
+}
+
+func (c *mockChain) ChainID() uint8 {
+	return c.id
+}
+
+type mockMessageStore struct {
+	lock     sync.Mutex
+	statuses map[uint64]MessageStatus
+	reasons  map[uint64]string
+	messages map[uint64]*Message
+}
+
+func (s *mockMessageStore) StoreMessageWithReason(m *Message, status MessageStatus, reason string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.reasons == nil {
+		s.reasons = make(map[uint64]string)
 	}
-	amount := new(big.Int).SetBytes(msg.Payload[0].([]byte))
-	if amount.Cmp(big.NewInt(14555)) != 0 {
-		t.Fatal(amount.String())
+	s.statuses[m.DepositNonce] = status
+	s.reasons[m.DepositNonce] = reason
+	s.storeMessage(m)
+	return nil
+}
+
+func (s *mockMessageStore) StoreMessage(m *Message, status MessageStatus) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.statuses[m.DepositNonce] = status
+	s.storeMessage(m)
+	return nil
+}
+
+func (s *mockMessageStore) storeMessage(m *Message) {
+	if s.messages == nil {
+		s.messages = make(map[uint64]*Message)
+	}
+	s.messages[m.DepositNonce] = m
+}
+
+// GetMessage returns nil message if only status was set by test
+func (s *mockMessageStore) GetMessage(m *Message) (*Message, MessageStatus, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	status, ok := s.statuses[m.DepositNonce]
+	if !ok {
+		return nil, MessageStatusPending, ErrMessageNotFound
+	}
+	return s.messages[m.DepositNonce], status, nil
+}
+
+func (s *mockMessageStore) GetMessageStatus(m *Message) (MessageStatus, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	status, ok := s.statuses[m.DepositNonce]
+	if !ok {
+		return MessageStatusPending, ErrMessageNotFound
 	}
-	msg2 := &Message{
-		Destination: 1,
-		Source:      2,
-		Payload: []interface{}{
-			big.NewInt(14555).Bytes(), // 145.55 tokens from 2nd chain
-		},
+	return status, nil
+}
+
+func (s *mockMessageStore) GetMessageReason(m *Message) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.statuses[m.DepositNonce]; !ok {
+		return "", ErrMessageNotFound
 	}
-	err = AdjustDecimalsForERC20AmountMessageProcessor(map[uint8]uint64{1: 18, 2: 2})(msg2)
+	return s.reasons[m.DepositNonce], nil
+}
+
+func (s *mockMessageStore) PendingMessages() ([]*Message, error) {
+	return nil, nil
+}
+
+func newTestChainMetrics() *metrics.ChainMetrics {
+	return &metrics.ChainMetrics{
+		AmountTransferred: metrics.NewAmountCounter(prometheus.Opts{Name: "amount"}, "resource_id"),
+		NumberOfTransfers: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "transfers"}, []string{"type"}),
+	}
+}
+
+func TestRouteRetriesAndMarksMessageDone(t *testing.T) {
+	MessageRetryInterval = time.Millisecond
+	dest := &mockChain{id: 2, failures: 2}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	r := NewRelayer([]RelayedChain{dest}, store)
+	r.addRelayedChain(dest)
+
+	msg := &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: big.NewInt(1).Bytes()}}
+	if !r.persistMessage(msg) {
+		t.Fatal("new message must be routed")
+	}
+	if store.statuses[1] != MessageStatusPending {
+		t.Fatal("new message must be stored as pending")
+	}
+
+	r.route(context.Background(), msg, true)
+	if dest.writes != 3 {
+		t.Fatalf("expected 3 write attempts, got %d", dest.writes)
+	}
+	if store.statuses[1] != MessageStatusDone {
+		t.Fatal("message must be marked done after successful write")
+	}
+	if r.persistMessage(msg) {
+		t.Fatal("already relayed message must not be routed again")
+	}
+}
+
+func TestPersistMessageReplacesOrphanedDeposit(t *testing.T) {
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	r := NewRelayer(nil, store)
+
+	orphaned := &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(1), Recipient: []byte{2}}}
+	if !r.persistMessage(orphaned) {
+		t.Fatal("new message must be routed")
+	}
+	if err := store.StoreMessage(orphaned, MessageStatusDone); err != nil {
+		t.Fatal(err)
+	}
+	if r.persistMessage(&Message{Source: 1, Destination: 2, DepositNonce: 1, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(1), Recipient: []byte{2}}}) {
+		t.Fatal("same deposit read again must not be routed")
+	}
+
+	// Canonical deposit reusing nonce of deposit from orphaned block
+	canonical := &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: FungibleTransfer, Payload: &FungibleTransferPayload{Amount: big.NewInt(3), Recipient: []byte{2}}}
+	if !r.persistMessage(canonical) {
+		t.Fatal("canonical deposit must be routed")
+	}
+	stored, status, err := store.GetMessage(canonical)
 	if err != nil {
-		t.Fatal()
+		t.Fatal(err)
+	}
+	if stored != canonical || status != MessageStatusPending {
+		t.Fatalf("expected canonical deposit to be stored as pending, got %+v with status %v", stored, status)
 	}
-	a2, _ := big.NewInt(0).SetString("145550000000000000000", 10)
-	amount2 := new(big.Int).SetBytes(msg2.Payload[0].([]byte))
-	if amount2.Cmp(a2) != 0 {
-		t.Fatal()
+}
+
+func TestRouteKeepsMessagePendingAfterRetryLimit(t *testing.T) {
+	MessageRetryInterval = time.Millisecond
+	dest := &mockChain{id: 2, failures: MessageRetryLimit + 1}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	r := NewRelayer([]RelayedChain{dest}, store)
+	r.addRelayedChain(dest)
+
+	msg := &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: big.NewInt(1).Bytes()}}
+	r.persistMessage(msg)
+	if !r.route(context.Background(), msg, true) {
+		t.Fatal("message must be retried after failed writes")
+	}
+	if dest.writes != MessageRetryLimit+1 {
+		t.Fatalf("expected %d write attempts, got %d", MessageRetryLimit+1, dest.writes)
+	}
+	if store.statuses[1] != MessageStatusPending {
+		t.Fatal("message must stay pending after failed writes")
+	}
+}
+
+func TestWorkersRequeueFailedMessages(t *testing.T) {
+	defer func(max time.Duration) { MessageRequeueMaxInterval = max }(MessageRequeueMaxInterval)
+	MessageRetryInterval = time.Millisecond
+	MessageRequeueMaxInterval = time.Millisecond * 2
+	// Writes fail until message is requeued twice, processing fails once more after that
+	dest := &mockChain{id: 2, failures: 2 * (MessageRetryLimit + 1)}
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	processed := 0
+	r := NewRelayer([]RelayedChain{dest}, store, NewProcessorFunc("flaky", func(m *Message) (ProcessorResult, error) {
+		processed++
+		if processed == 3 {
+			return ProcessorResult{}, errors.New("processing failed")
+		}
+		return Continue(), nil
+	}))
+	r.addRelayedChain(dest)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := r.startWorkers(ctx, dest)
+
+	msg := &Message{Source: 1, Destination: 2, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: big.NewInt(1).Bytes()}}
+	r.persistMessage(msg)
+	pool.enqueue(msg)
+	waitForStatus(t, store, 1, MessageStatusDone)
+	if dest.writes != 2*(MessageRetryLimit+1)+1 || processed != 4 {
+		t.Fatalf("unexpected %d writes and %d processing attempts", dest.writes, processed)
+	}
+	if attempts := pool.failedAttempts(msg); attempts != 0 {
+		t.Fatalf("expected delivered message to be forgotten, got %d failed attempts", attempts)
+	}
+}
+
+func TestRequeueDelayIsCapped(t *testing.T) {
+	defer func(interval, max time.Duration) {
+		MessageRetryInterval, MessageRequeueMaxInterval = interval, max
+	}(MessageRetryInterval, MessageRequeueMaxInterval)
+	MessageRetryInterval = time.Second
+	MessageRequeueMaxInterval = time.Second * 10
+
+	for attempt, expected := range []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 8, time.Second * 10, time.Second * 10} {
+		if delay := requeueDelay(attempt); delay != expected {
+			t.Fatalf("expected delay %s after %d attempts, got %s", expected, attempt, delay)
+		}
+	}
+	if delay := requeueDelay(1000); delay != MessageRequeueMaxInterval {
+		t.Fatalf("expected capped delay, got %s", delay)
+	}
+}
+
+// pollingChain sends its messages once, then waits for ctx to be done. Writes block until release is closed or write ctx is done.
+type pollingChain struct {
+	id       uint8
+	messages []*Message
+	writing  chan struct{}
+	written  chan *Message
+	release  chan struct{}
+}
+
+func newPollingChain(messages ...*Message) *pollingChain {
+	return &pollingChain{id: 1, messages: messages, writing: make(chan struct{}, 1), written: make(chan *Message, 1), release: make(chan struct{})}
+}
+
+func (c *pollingChain) PollEvents(ctx context.Context, sysErr chan<- error, eventsChan chan *Message) {
+	for _, m := range c.messages {
+		eventsChan <- m
+	}
+	<-ctx.Done()
+}
+
+func (c *pollingChain) Write(ctx context.Context, message *Message) error {
+	c.writing <- struct{}{}
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	c.written <- message
+	return nil
+}
+
+func (c *pollingChain) ChainID() uint8 {
+	return c.id
+}
+
+func TestStopWaitsForInFlightMessages(t *testing.T) {
+	msg := &Message{Source: 1, Destination: 1, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}
+	chain := newPollingChain(msg)
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	r := NewRelayer([]RelayedChain{chain}, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.Start(ctx, make(chan error, 1))
+	<-chain.writing
+	cancel()
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- r.Stop(context.Background()) }()
+	select {
+	case err := <-stopped:
+		t.Fatalf("stop returned while message is written: %v", err)
+	case <-time.After(time.Millisecond * 10):
+	}
+
+	close(chain.release)
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	if written := <-chain.written; !reflect.DeepEqual(written, msg) {
+		t.Fatalf("unexpected written message %+v", written)
+	}
+	waitForStatus(t, store, 1, MessageStatusDone)
+}
+
+func TestStopCancelsWritesWhenTimedOut(t *testing.T) {
+	msg := &Message{Source: 1, Destination: 1, DepositNonce: 1, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}
+	chain := newPollingChain(msg)
+	store := &mockMessageStore{statuses: make(map[uint64]MessageStatus)}
+	r := NewRelayer([]RelayedChain{chain}, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.Start(ctx, make(chan error, 1))
+	<-chain.writing
+	cancel()
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancelStop()
+	if err := r.Stop(stopCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error while message is written, got %v", err)
+	}
+
+	// Stop returned after workers, so nothing is written or stored anymore
+	select {
+	case m := <-chain.written:
+		t.Fatalf("unexpected written message %+v", m)
+	default:
+	}
+	if status := store.statuses[1]; status != MessageStatusPending {
+		t.Fatalf("expected cancelled message to stay pending, got %v", status)
+	}
+}
+
+func waitForStatus(t *testing.T, store *mockMessageStore, nonce uint64, expected MessageStatus) {
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		status, err := store.GetMessageStatus(&Message{DepositNonce: nonce})
+		if err == nil && status == expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for message %d status %v", nonce, expected)
+		}
+		time.Sleep(time.Millisecond)
 	}
 }
@@ -0,0 +1,322 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog/log"
+)
+
+const TransferLimitsProcessorType = "transferLimits"
+
+const transferRecordPrefix = "transferlimits:"
+
+// TransferLimitWindow is the rolling window of daily volume limits
+var TransferLimitWindow = time.Hour * 24
+
+// TransferLimit limits fungible transfers of a resource. Limit applies to all routes unless Source or Destination
+// is set, unset amounts are not limited. Amounts are compared as they are when limits processor runs, so after
+// decimals are adjusted if adjustDecimals processor runs before it.
+type TransferLimit struct {
+	ResourceID  [32]byte
+	Source      *uint8
+	Destination *uint8
+	// MaxAmount is the largest amount of a single transfer
+	MaxAmount *big.Int
+	// DailyVolume is the largest amount transferred on the route in TransferLimitWindow
+	DailyVolume *big.Int
+	// DailyRecipientVolume is the largest amount transferred on the route to a single recipient in TransferLimitWindow
+	DailyRecipientVolume *big.Int
+}
+
+func (l *TransferLimit) matches(resourceID [32]byte, source, destination uint8) bool {
+	return l.ResourceID == resourceID &&
+		(l.Source == nil || *l.Source == source) &&
+		(l.Destination == nil || *l.Destination == destination)
+}
+
+// TransferLimitsDB persists transfers counted towards daily volume so limits hold across relayer restarts
+type TransferLimitsDB interface {
+	SetByKey(key []byte, value []byte) error
+	DeleteByKey(key []byte) error
+	IterateByPrefix(prefix []byte, f func(key []byte, value []byte) error) error
+}
+
+type transferRecord struct {
+	ResourceID  [32]byte
+	Source      uint8
+	Destination uint8
+	Recipient   []byte
+	Amount      *big.Int
+	Time        time.Time
+
+	reserved bool // pending transfer passed limits, its amount counts towards volume until it is written or dropped
+}
+
+// transferLedger keeps transfers written in the last TransferLimitWindow, it is shared by limits processors built by one factory
+type transferLedger struct {
+	db  TransferLimitsDB
+	now func() time.Time
+
+	lock    sync.Mutex
+	records map[string]*transferRecord
+	pending map[string]*transferRecord // processed transfers that are not written yet, reserved ones count towards volume
+}
+
+func newTransferLedger(db TransferLimitsDB, now func() time.Time) (*transferLedger, error) {
+	ledger := &transferLedger{db: db, now: now, records: make(map[string]*transferRecord), pending: make(map[string]*transferRecord)}
+	err := db.IterateByPrefix([]byte(transferRecordPrefix), func(key []byte, value []byte) error {
+		record := &transferRecord{}
+		if err := gob.NewDecoder(bytes.NewReader(value)).Decode(record); err != nil {
+			return fmt.Errorf("decoding transfer record %s: %w", key, err)
+		}
+		ledger.records[string(key)] = record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ledger.prune()
+	return ledger, nil
+}
+
+type transferLimitsProcessor struct {
+	limits []TransferLimit
+	ledger *transferLedger
+}
+
+// NewTransferLimitsProcessor creates processor that quarantines fungible transfers over any of limits.
+// Transfers are counted towards daily volume and persisted in db once they are written to destination chain,
+// including quarantined transfers that were approved.
+func NewTransferLimitsProcessor(limits []TransferLimit, db TransferLimitsDB) (MessageProcessor, error) {
+	ledger, err := newTransferLedger(db, time.Now)
+	if err != nil {
+		return nil, err
+	}
+	return &transferLimitsProcessor{limits: limits, ledger: ledger}, nil
+}
+
+// NewTransferLimitsProcessorFactory returns factory of transfer limits processors persisting transfers in db.
+// Processors built by the factory count transfers together, so limits of one chain see transfers to other chains.
+// Processor is configured from chain config entry like
+// {"type": "transferLimits", "limits": [{"resourceId": "0x00..01", "source": 1, "maxAmount": "1000", "dailyVolume": "10000", "dailyRecipientVolume": "2000"}]}
+// with amounts as decimal strings.
+func NewTransferLimitsProcessorFactory(db TransferLimitsDB) ProcessorFactory {
+	var (
+		once      sync.Once
+		ledger    *transferLedger
+		ledgerErr error
+	)
+	return func(params map[string]interface{}) (MessageProcessor, error) {
+		config := struct {
+			Limits []struct {
+				ResourceID           string `mapstructure:"resourceId"`
+				Source               *uint8 `mapstructure:"source"`
+				Destination          *uint8 `mapstructure:"destination"`
+				MaxAmount            string `mapstructure:"maxAmount"`
+				DailyVolume          string `mapstructure:"dailyVolume"`
+				DailyRecipientVolume string `mapstructure:"dailyRecipientVolume"`
+			} `mapstructure:"limits"`
+		}{}
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{WeaklyTypedInput: true, Result: &config})
+		if err != nil {
+			return nil, err
+		}
+		err = decoder.Decode(params)
+		if err != nil {
+			return nil, err
+		}
+		if len(config.Limits) == 0 {
+			return nil, errors.New("limits are empty")
+		}
+		limits := make([]TransferLimit, len(config.Limits))
+		for i, l := range config.Limits {
+			rID, err := hex.DecodeString(strings.TrimPrefix(l.ResourceID, "0x"))
+			if err != nil || len(rID) != 32 {
+				return nil, fmt.Errorf("invalid resourceId %s", l.ResourceID)
+			}
+			copy(limits[i].ResourceID[:], rID)
+			limits[i].Source = l.Source
+			limits[i].Destination = l.Destination
+			if limits[i].MaxAmount, err = parseLimitAmount(l.MaxAmount); err != nil {
+				return nil, fmt.Errorf("invalid maxAmount: %w", err)
+			}
+			if limits[i].DailyVolume, err = parseLimitAmount(l.DailyVolume); err != nil {
+				return nil, fmt.Errorf("invalid dailyVolume: %w", err)
+			}
+			if limits[i].DailyRecipientVolume, err = parseLimitAmount(l.DailyRecipientVolume); err != nil {
+				return nil, fmt.Errorf("invalid dailyRecipientVolume: %w", err)
+			}
+		}
+		once.Do(func() {
+			ledger, ledgerErr = newTransferLedger(db, time.Now)
+		})
+		if ledgerErr != nil {
+			return nil, ledgerErr
+		}
+		return &transferLimitsProcessor{limits: limits, ledger: ledger}, nil
+	}
+}
+
+// parseLimitAmount returns nil for empty amount, which is not limited
+func parseLimitAmount(amount string) (*big.Int, error) {
+	if amount == "" {
+		return nil, nil
+	}
+	a, ok := big.NewInt(0).SetString(amount, 10)
+	if !ok || a.Sign() < 0 {
+		return nil, fmt.Errorf("%s is not a non-negative decimal number", amount)
+	}
+	return a, nil
+}
+
+func (p *transferLimitsProcessor) Name() string {
+	return TransferLimitsProcessorType
+}
+
+func (p *transferLimitsProcessor) Process(m *Message) (ProcessorResult, error) {
+	if m.Type != FungibleTransfer {
+		return Continue(), nil
+	}
+	payload, err := m.FungibleTransferPayload()
+	if err != nil {
+		return ProcessorResult{}, err
+	}
+
+	p.ledger.lock.Lock()
+	defer p.ledger.lock.Unlock()
+	p.ledger.prune()
+	key := transferRecordKey(m)
+	if _, ok := p.ledger.records[key]; ok {
+		// Replayed message was already counted
+		return Continue(), nil
+	}
+
+	// Transfer is recorded once it is written, whether it passes limits or is approved after quarantine.
+	// Processing it again replaces its reservation, so retried transfer is not counted twice.
+	record := &transferRecord{
+		ResourceID:  m.ResourceId,
+		Source:      m.Source,
+		Destination: m.Destination,
+		Recipient:   payload.Recipient,
+		Amount:      payload.Amount,
+		Time:        p.ledger.now(),
+	}
+	p.ledger.pending[key] = record
+
+	for i := range p.limits {
+		l := &p.limits[i]
+		if !l.matches(m.ResourceId, m.Source, m.Destination) {
+			continue
+		}
+		if l.MaxAmount != nil && payload.Amount.Cmp(l.MaxAmount) > 0 {
+			return Quarantine(fmt.Sprintf("amount %s is over limit %s", payload.Amount, l.MaxAmount)), nil
+		}
+		volume, recipientVolume := p.ledger.volume(l, payload.Recipient)
+		volume.Add(volume, payload.Amount)
+		if l.DailyVolume != nil && volume.Cmp(l.DailyVolume) > 0 {
+			return Quarantine(fmt.Sprintf("daily volume %s is over limit %s", volume, l.DailyVolume)), nil
+		}
+		recipientVolume.Add(recipientVolume, payload.Amount)
+		if l.DailyRecipientVolume != nil && recipientVolume.Cmp(l.DailyRecipientVolume) > 0 {
+			return Quarantine(fmt.Sprintf("daily volume %s of recipient %x is over limit %s", recipientVolume, payload.Recipient, l.DailyRecipientVolume)), nil
+		}
+	}
+
+	record.reserved = true
+	return Continue(), nil
+}
+
+// MessageWritten counts transfer processed earlier towards daily volume
+func (p *transferLimitsProcessor) MessageWritten(m *Message) error {
+	p.ledger.lock.Lock()
+	defer p.ledger.lock.Unlock()
+	key := transferRecordKey(m)
+	record, ok := p.ledger.pending[key]
+	if !ok {
+		return nil
+	}
+	delete(p.ledger.pending, key)
+	record.Time = p.ledger.now()
+	return p.ledger.add(key, record)
+}
+
+// MessageNotWritten drops reservation of transfer processed earlier
+func (p *transferLimitsProcessor) MessageNotWritten(m *Message) {
+	p.ledger.lock.Lock()
+	defer p.ledger.lock.Unlock()
+	delete(p.ledger.pending, transferRecordKey(m))
+}
+
+// add counts record and persists it, record is counted until restart even if storing it fails
+func (l *transferLedger) add(key string, record *transferRecord) error {
+	l.records[key] = record
+	value := bytes.Buffer{}
+	if err := gob.NewEncoder(&value).Encode(record); err != nil {
+		return err
+	}
+	if err := l.db.SetByKey([]byte(key), value.Bytes()); err != nil {
+		return fmt.Errorf("storing transfer record: %w", err)
+	}
+	return nil
+}
+
+// volume returns amount transferred in window on routes of limit and reserved by transfers not written yet,
+// in total and to recipient
+func (l *transferLedger) volume(limit *TransferLimit, recipient []byte) (*big.Int, *big.Int) {
+	volume := big.NewInt(0)
+	recipientVolume := big.NewInt(0)
+	count := func(r *transferRecord) {
+		if !limit.matches(r.ResourceID, r.Source, r.Destination) {
+			return
+		}
+		volume.Add(volume, r.Amount)
+		if bytes.Equal(r.Recipient, recipient) {
+			recipientVolume.Add(recipientVolume, r.Amount)
+		}
+	}
+	for _, r := range l.records {
+		count(r)
+	}
+	for _, r := range l.pending {
+		if r.reserved {
+			count(r)
+		}
+	}
+	return volume, recipientVolume
+}
+
+// prune removes records that are out of window, and pending records of transfers not written in window
+func (l *transferLedger) prune() {
+	since := l.now().Add(-TransferLimitWindow)
+	for key, r := range l.pending {
+		if !r.Time.After(since) {
+			delete(l.pending, key)
+		}
+	}
+	for key, r := range l.records {
+		if r.Time.After(since) {
+			continue
+		}
+		// Record left in db is pruned again on the next start
+		if err := l.db.DeleteByKey([]byte(key)); err != nil {
+			log.Warn().Err(err).Msgf("removing transfer record %s", key)
+		}
+		delete(l.records, key)
+	}
+}
+
+func transferRecordKey(m *Message) string {
+	return fmt.Sprintf("%s%x:%d:%d:%d", transferRecordPrefix, m.ResourceId, m.Source, m.Destination, m.DepositNonce)
+}
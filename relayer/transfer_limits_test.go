@@ -0,0 +1,248 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockLimitsDB struct {
+	values  map[string][]byte
+	iterate int // number of IterateByPrefix calls
+}
+
+func (db *mockLimitsDB) SetByKey(key []byte, value []byte) error {
+	db.values[string(key)] = value
+	return nil
+}
+
+func (db *mockLimitsDB) DeleteByKey(key []byte) error {
+	delete(db.values, string(key))
+	return nil
+}
+
+func (db *mockLimitsDB) IterateByPrefix(prefix []byte, f func(key []byte, value []byte) error) error {
+	db.iterate++
+	for k, v := range db.values {
+		if strings.HasPrefix(k, string(prefix)) {
+			if err := f([]byte(k), v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func transfer(nonce uint64, amount int64, recipient byte) *Message {
+	payload := &FungibleTransferPayload{Amount: big.NewInt(amount), Recipient: []byte{recipient}}
+	return &Message{Source: 1, Destination: 2, DepositNonce: nonce, ResourceId: [32]byte{1}, Type: FungibleTransfer, Payload: payload}
+}
+
+func newTestLimitsProcessor(t *testing.T, db *mockLimitsDB, now *time.Time, limits ...TransferLimit) *transferLimitsProcessor {
+	ledger, err := newTransferLedger(db, func() time.Time { return *now })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &transferLimitsProcessor{limits: limits, ledger: ledger}
+}
+
+func expectOutcome(t *testing.T, p MessageProcessor, m *Message, expected ProcessorOutcome) {
+	t.Helper()
+	result, err := p.Process(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != expected {
+		t.Fatalf("message %d: expected outcome %s, got %s (%s)", m.DepositNonce, expected, result.Outcome, result.Reason)
+	}
+}
+
+// relay processes message and marks it written if it passed limits, or not written otherwise as relayer does
+func relay(t *testing.T, p *transferLimitsProcessor, m *Message, expected ProcessorOutcome) {
+	t.Helper()
+	expectOutcome(t, p, m, expected)
+	if expected != OutcomeContinue {
+		p.MessageNotWritten(m)
+		return
+	}
+	if err := p.MessageWritten(m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransferLimitsMaxAmount(t *testing.T) {
+	now := time.Now()
+	source := uint8(1)
+	p := newTestLimitsProcessor(t, &mockLimitsDB{values: map[string][]byte{}}, &now, TransferLimit{ResourceID: [32]byte{1}, Source: &source, MaxAmount: big.NewInt(100)})
+
+	expectOutcome(t, p, transfer(1, 100, 1), OutcomeContinue)
+	expectOutcome(t, p, transfer(2, 101, 1), OutcomeQuarantine)
+
+	otherRoute := transfer(3, 101, 1)
+	otherRoute.Source = 3
+	expectOutcome(t, p, otherRoute, OutcomeContinue)
+	otherResource := transfer(4, 101, 1)
+	otherResource.ResourceId = [32]byte{2}
+	expectOutcome(t, p, otherResource, OutcomeContinue)
+	expectOutcome(t, p, &Message{Source: 1, Destination: 2, DepositNonce: 5, ResourceId: [32]byte{1}, Type: GenericTransfer}, OutcomeContinue)
+}
+
+func TestTransferLimitsDailyVolume(t *testing.T) {
+	now := time.Now()
+	db := &mockLimitsDB{values: map[string][]byte{}}
+	limit := TransferLimit{ResourceID: [32]byte{1}, DailyVolume: big.NewInt(100), DailyRecipientVolume: big.NewInt(60)}
+	p := newTestLimitsProcessor(t, db, &now, limit)
+
+	relay(t, p, transfer(1, 50, 1), OutcomeContinue)
+	relay(t, p, transfer(2, 20, 1), OutcomeQuarantine)
+	// Replayed message is not counted twice
+	relay(t, p, transfer(1, 50, 1), OutcomeContinue)
+	relay(t, p, transfer(3, 50, 2), OutcomeContinue)
+	relay(t, p, transfer(4, 1, 3), OutcomeQuarantine)
+
+	// Volume is counted after restart
+	now = now.Add(time.Hour)
+	p = newTestLimitsProcessor(t, db, &now, limit)
+	relay(t, p, transfer(5, 1, 3), OutcomeQuarantine)
+
+	now = now.Add(TransferLimitWindow)
+	relay(t, p, transfer(6, 60, 3), OutcomeContinue)
+	if len(db.values) != 1 {
+		t.Fatalf("expected transfers out of window to be removed, got %d records", len(db.values))
+	}
+}
+
+func TestTransferLimitsDailyRecipientVolume(t *testing.T) {
+	now := time.Now()
+	db := &mockLimitsDB{values: map[string][]byte{}}
+	p := newTestLimitsProcessor(t, db, &now, TransferLimit{ResourceID: [32]byte{1}, DailyRecipientVolume: big.NewInt(100)})
+
+	relay(t, p, transfer(1, 60, 1), OutcomeContinue)
+	relay(t, p, transfer(2, 40, 1), OutcomeContinue)
+	relay(t, p, transfer(3, 1, 1), OutcomeQuarantine)
+	// Other recipients have their own volume
+	relay(t, p, transfer(4, 100, 2), OutcomeContinue)
+
+	now = now.Add(TransferLimitWindow / 2)
+	relay(t, p, transfer(5, 1, 1), OutcomeQuarantine)
+	// Transfers of the first recipient leave the window
+	now = now.Add(TransferLimitWindow / 2)
+	relay(t, p, transfer(6, 100, 1), OutcomeContinue)
+}
+
+func TestTransferLimitsCountWrittenTransfersOnly(t *testing.T) {
+	now := time.Now()
+	db := &mockLimitsDB{values: map[string][]byte{}}
+	p := newTestLimitsProcessor(t, db, &now, TransferLimit{ResourceID: [32]byte{1}, DailyVolume: big.NewInt(100)})
+
+	// Transfer that failed to be written is not counted
+	expectOutcome(t, p, transfer(1, 100, 1), OutcomeContinue)
+	if len(db.values) != 0 {
+		t.Fatalf("expected transfer to be recorded after it is written, got %d records", len(db.values))
+	}
+	p.MessageNotWritten(transfer(1, 100, 1))
+	relay(t, p, transfer(2, 80, 1), OutcomeContinue)
+	relay(t, p, transfer(3, 30, 1), OutcomeQuarantine)
+
+	// Approved transfer is counted once written
+	approved := transfer(3, 30, 1)
+	expectOutcome(t, p, approved, OutcomeQuarantine)
+	if err := p.MessageWritten(approved); err != nil {
+		t.Fatal(err)
+	}
+	relay(t, p, transfer(4, 1, 1), OutcomeQuarantine)
+	if len(db.values) != 2 {
+		t.Fatalf("expected 2 written transfers to be recorded, got %d records", len(db.values))
+	}
+}
+
+func TestTransferLimitsCountReservedTransfers(t *testing.T) {
+	now := time.Now()
+	p := newTestLimitsProcessor(t, &mockLimitsDB{values: map[string][]byte{}}, &now, TransferLimit{ResourceID: [32]byte{1}, DailyVolume: big.NewInt(100)})
+
+	// Both transfers are processed before either is written
+	expectOutcome(t, p, transfer(1, 60, 1), OutcomeContinue)
+	expectOutcome(t, p, transfer(2, 60, 2), OutcomeQuarantine)
+	p.MessageNotWritten(transfer(2, 60, 2))
+
+	// Retried transfer replaces its own reservation
+	expectOutcome(t, p, transfer(1, 60, 1), OutcomeContinue)
+	expectOutcome(t, p, transfer(3, 40, 2), OutcomeContinue)
+
+	// Dropped transfer releases its reservation
+	p.MessageNotWritten(transfer(3, 40, 2))
+	relay(t, p, transfer(1, 60, 1), OutcomeContinue)
+	relay(t, p, transfer(4, 40, 2), OutcomeContinue)
+	relay(t, p, transfer(5, 1, 2), OutcomeQuarantine)
+}
+
+func TestTransferLimitsProcessorFactorySharesLedger(t *testing.T) {
+	db := &mockLimitsDB{values: map[string][]byte{}}
+	factory := NewTransferLimitsProcessorFactory(db)
+	params := map[string]interface{}{
+		"limits": []interface{}{
+			map[string]interface{}{
+				"resourceId":           "0x0100000000000000000000000000000000000000000000000000000000000000",
+				"dailyRecipientVolume": "100",
+			},
+		},
+	}
+
+	processors := make([]*transferLimitsProcessor, 4)
+	wg := sync.WaitGroup{}
+	for i := range processors {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := factory(params)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			processors[i] = p.(*transferLimitsProcessor)
+		}(i)
+	}
+	wg.Wait()
+	if db.iterate != 1 {
+		t.Fatalf("expected ledger to be loaded once, got %d loads", db.iterate)
+	}
+	for _, p := range processors[1:] {
+		if p.ledger != processors[0].ledger {
+			t.Fatal("expected processors of factory to share ledger")
+		}
+	}
+
+	relay(t, processors[0], transfer(1, 100, 1), OutcomeContinue)
+	relay(t, processors[1], transfer(2, 1, 1), OutcomeQuarantine)
+}
+
+func TestTransferLimitsProcessorFromConfig(t *testing.T) {
+	factory := NewTransferLimitsProcessorFactory(&mockLimitsDB{values: map[string][]byte{}})
+	p, err := factory(map[string]interface{}{
+		"limits": []interface{}{
+			map[string]interface{}{
+				"resourceid": "0x0100000000000000000000000000000000000000000000000000000000000000",
+				"source":     1.0,
+				"maxamount":  "1000",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectOutcome(t, p, transfer(1, 1001, 1), OutcomeQuarantine)
+
+	_, err = factory(map[string]interface{}{"limits": []interface{}{map[string]interface{}{"resourceId": "0x01", "maxAmount": "1000"}}})
+	if err == nil {
+		t.Fatal("expected error for short resource ID")
+	}
+	_, err = factory(map[string]interface{}{"limits": []interface{}{map[string]interface{}{"resourceId": "0x0100000000000000000000000000000000000000000000000000000000000000", "maxAmount": "1e3"}}})
+	if err == nil {
+		t.Fatal("expected error for amount that is not decimal")
+	}
+}
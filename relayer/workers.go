@@ -0,0 +1,219 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// WorkerConfig configures workers writing messages to a destination chain
+type WorkerConfig struct {
+	// Concurrency is the number of messages written to destination at the same time
+	Concurrency int
+	// QueueSize is the number of messages waiting for a worker. While queue is full, further messages to the chain
+	// wait in its backlog, messages to other chains are not held up
+	QueueSize int
+	// OrderByNonce writes messages from one source chain one at a time in the order they were read, which is deposit nonce order
+	OrderByNonce bool
+}
+
+// DefaultWorkerConfig is used for destination chains that do not configure workers, and for unset fields of those that do
+var DefaultWorkerConfig = WorkerConfig{Concurrency: 4, QueueSize: 100}
+
+// WorkerConfigurer is implemented by chains that configure workers writing messages to them
+type WorkerConfigurer interface {
+	WorkerConfig() WorkerConfig
+}
+
+func workerConfig(c RelayedChain) WorkerConfig {
+	config := DefaultWorkerConfig
+	wc, ok := c.(WorkerConfigurer)
+	if !ok {
+		return config
+	}
+	custom := wc.WorkerConfig()
+	if custom.Concurrency > 0 {
+		config.Concurrency = custom.Concurrency
+	}
+	if custom.QueueSize > 0 {
+		config.QueueSize = custom.QueueSize
+	}
+	config.OrderByNonce = custom.OrderByNonce
+	return config
+}
+
+// workerPool writes messages to a single destination chain. Without ordering all workers share one queue,
+// with ordering every worker has its own queue and messages of a source chain always go to the same worker.
+// Messages are passed to queues by dispatcher of the pool from its backlog, so a slow destination
+// only blocks its own dispatcher. Messages in backlog are already stored as pending.
+type workerPool struct {
+	queues  []chan *Message
+	depth   prometheus.Gauge
+	ordered bool
+
+	lock     sync.Mutex
+	backlog  []*Message
+	ready    chan struct{}   // signals dispatcher that backlog is not empty
+	attempts map[poolKey]int // failed attempts of requeued messages
+}
+
+type poolKey struct {
+	source uint8
+	nonce  uint64
+}
+
+// startWorkers starts workers of destination chain c, workers stop once ctx is done
+func (r *Relayer) startWorkers(ctx context.Context, c RelayedChain) *workerPool {
+	config := workerConfig(c)
+	queues := 1
+	if config.OrderByNonce {
+		queues = config.Concurrency
+	}
+	pool := &workerPool{
+		queues:   make([]chan *Message, queues),
+		depth:    r.bridgeMetrics.DestinationQueueDepth(c.ChainID()),
+		ordered:  config.OrderByNonce,
+		ready:    make(chan struct{}, 1),
+		attempts: make(map[poolKey]int),
+	}
+	for i := range pool.queues {
+		pool.queues[i] = make(chan *Message, config.QueueSize)
+	}
+	log.Debug().Uint8("chainID", c.ChainID()).Msgf("Starting %d workers, queue size %d, ordered by nonce: %t", config.Concurrency, config.QueueSize, config.OrderByNonce)
+	for i := 0; i < config.Concurrency; i++ {
+		r.routes.Add(1)
+		go func(queue <-chan *Message) {
+			defer r.routes.Done()
+			r.work(ctx, pool, queue)
+		}(pool.queues[i%queues])
+	}
+	r.routes.Add(1)
+	go func() {
+		defer r.routes.Done()
+		pool.dispatch(ctx)
+	}()
+	return pool
+}
+
+// work routes messages from queue until ctx is done. Messages left in queue stay pending.
+func (r *Relayer) work(ctx context.Context, pool *workerPool, queue <-chan *Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-queue:
+			pool.depth.Dec()
+			r.deliver(ctx, pool, m)
+		}
+	}
+}
+
+// deliver routes message and retries it with backoff until it is no longer pending. Messages ordered by nonce
+// are retried by the same worker to keep their order, others are requeued so the worker takes the next message meanwhile.
+func (r *Relayer) deliver(ctx context.Context, pool *workerPool, m *Message) {
+	for attempt := pool.failedAttempts(m); ; attempt++ {
+		if !r.route(ctx, m, attempt == 0) {
+			pool.forget(m)
+			return
+		}
+		delay := requeueDelay(attempt)
+		log.Warn().Uint8("src", m.Source).Uint8("dst", m.Destination).Uint64("nonce", m.DepositNonce).Msgf("Message retried in %s", delay)
+		if !pool.ordered {
+			pool.requeue(ctx, m, attempt+1, delay)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// requeueDelay doubles MessageRetryInterval with every failed attempt up to MessageRequeueMaxInterval
+func requeueDelay(attempt int) time.Duration {
+	delay := MessageRetryInterval
+	for i := 0; i < attempt && delay < MessageRequeueMaxInterval; i++ {
+		delay *= 2
+	}
+	if delay > MessageRequeueMaxInterval {
+		return MessageRequeueMaxInterval
+	}
+	return delay
+}
+
+func (p *workerPool) failedAttempts(m *Message) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.attempts[poolKey{m.Source, m.DepositNonce}]
+}
+
+func (p *workerPool) forget(m *Message) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.attempts, poolKey{m.Source, m.DepositNonce})
+}
+
+// requeue enqueues message again after delay unless ctx is done first
+func (p *workerPool) requeue(ctx context.Context, m *Message, attempts int, delay time.Duration) {
+	p.lock.Lock()
+	p.attempts[poolKey{m.Source, m.DepositNonce}] = attempts
+	p.lock.Unlock()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+			p.enqueue(m)
+		}
+	}()
+}
+
+// enqueue adds message to backlog of the pool without blocking
+func (p *workerPool) enqueue(m *Message) {
+	p.lock.Lock()
+	p.backlog = append(p.backlog, m)
+	p.lock.Unlock()
+	p.depth.Inc()
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next removes the oldest message from backlog, returns nil if backlog is empty
+func (p *workerPool) next() *Message {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(p.backlog) == 0 {
+		return nil
+	}
+	m := p.backlog[0]
+	p.backlog[0] = nil
+	p.backlog = p.backlog[1:]
+	return m
+}
+
+// dispatch moves messages from backlog to worker queues in the order they were enqueued, waiting while
+// a queue is full. Returns once ctx is done, messages left in backlog stay pending.
+func (p *workerPool) dispatch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.ready:
+		}
+		for m := p.next(); m != nil; m = p.next() {
+			select {
+			case p.queues[int(m.Source)%len(p.queues)] <- m:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
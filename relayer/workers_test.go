@@ -0,0 +1,208 @@
+// Copyright 2021 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// workerChain is source and destination of its messages. It records written messages and the highest number of concurrent writes.
+type workerChain struct {
+	id       uint8
+	config   WorkerConfig
+	messages []*Message
+
+	lock          sync.Mutex
+	written       []*Message
+	writing       int
+	maxConcurrent int
+}
+
+func (c *workerChain) PollEvents(ctx context.Context, sysErr chan<- error, eventsChan chan *Message) {
+	for _, m := range c.messages {
+		eventsChan <- m
+	}
+	<-ctx.Done()
+}
+
+func (c *workerChain) Write(ctx context.Context, message *Message) error {
+	c.lock.Lock()
+	c.writing++
+	if c.writing > c.maxConcurrent {
+		c.maxConcurrent = c.writing
+	}
+	c.lock.Unlock()
+
+	// later nonces are written faster, so unordered writes would likely finish out of order
+	time.Sleep(time.Millisecond * time.Duration(20-message.DepositNonce%20))
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writing--
+	c.written = append(c.written, message)
+	return nil
+}
+
+func (c *workerChain) ChainID() uint8 {
+	return c.id
+}
+
+func (c *workerChain) WorkerConfig() WorkerConfig {
+	return c.config
+}
+
+func (c *workerChain) writtenMessages() []*Message {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*Message{}, c.written...)
+}
+
+func runWorkers(t *testing.T, chain *workerChain) {
+	r := NewRelayer([]RelayedChain{chain}, &mockMessageStore{statuses: make(map[uint64]MessageStatus)})
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.Start(ctx, make(chan error, 1))
+
+	deadline := time.Now().Add(time.Second * 5)
+	for len(chain.writtenMessages()) != len(chain.messages) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for writes, written %d of %d messages", len(chain.writtenMessages()), len(chain.messages))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkersLimitConcurrency(t *testing.T) {
+	chain := &workerChain{id: 1, config: WorkerConfig{Concurrency: 3, QueueSize: 1}}
+	for nonce := uint64(1); nonce <= 12; nonce++ {
+		chain.messages = append(chain.messages, &Message{Source: 2, Destination: 1, DepositNonce: nonce, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}})
+	}
+
+	runWorkers(t, chain)
+	if chain.maxConcurrent != 3 {
+		t.Fatalf("expected 3 concurrent writes, got %d", chain.maxConcurrent)
+	}
+}
+
+func TestWorkersOrderByNonce(t *testing.T) {
+	chain := &workerChain{id: 1, config: WorkerConfig{Concurrency: 4, OrderByNonce: true}}
+	// mock message store tells messages apart by nonce only
+	for nonce := uint64(1); nonce <= 10; nonce++ {
+		chain.messages = append(chain.messages,
+			&Message{Source: 2, Destination: 1, DepositNonce: nonce, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}},
+			&Message{Source: 3, Destination: 1, DepositNonce: nonce + 10, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}})
+	}
+
+	runWorkers(t, chain)
+	last := make(map[uint8]uint64)
+	for _, m := range chain.writtenMessages() {
+		if m.DepositNonce <= last[m.Source] {
+			t.Fatalf("message %d from source %d written after message %d", m.DepositNonce, m.Source, last[m.Source])
+		}
+		last[m.Source] = m.DepositNonce
+	}
+	// Two sources are written by two workers at most
+	if chain.maxConcurrent > 2 {
+		t.Fatalf("expected at most 2 concurrent writes, got %d", chain.maxConcurrent)
+	}
+}
+
+func TestDispatchKeepsBacklogWhileQueueIsFull(t *testing.T) {
+	depth := prometheus.NewGauge(prometheus.GaugeOpts{Name: "depth"})
+	queue := make(chan *Message, 1)
+	pool := &workerPool{queues: []chan *Message{queue}, depth: depth, ready: make(chan struct{}, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.dispatch(ctx)
+
+	// enqueue does not wait for dispatcher
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		pool.enqueue(&Message{DepositNonce: nonce})
+	}
+	if testutil.ToFloat64(depth) != 3 {
+		t.Fatalf("expected queue depth 3, got %f", testutil.ToFloat64(depth))
+	}
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		select {
+		case m := <-queue:
+			if m.DepositNonce != nonce {
+				t.Fatalf("expected message %d, got %d", nonce, m.DepositNonce)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("timed out waiting for message %d", nonce)
+		}
+	}
+}
+
+// stuckChain never finishes writes until write ctx is done
+type stuckChain struct {
+	id uint8
+}
+
+func (c *stuckChain) PollEvents(ctx context.Context, sysErr chan<- error, eventsChan chan *Message) {
+	<-ctx.Done()
+}
+
+func (c *stuckChain) Write(ctx context.Context, message *Message) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *stuckChain) ChainID() uint8 {
+	return c.id
+}
+
+func (c *stuckChain) WorkerConfig() WorkerConfig {
+	return WorkerConfig{Concurrency: 1, QueueSize: 1}
+}
+
+func TestStuckDestinationDoesNotBlockOthers(t *testing.T) {
+	stuck := &stuckChain{id: 1}
+	chain := &workerChain{id: 2}
+	// first message to stuck chain is being written, second fills its queue, the rest wait in backlog
+	for nonce := uint64(1); nonce <= 5; nonce++ {
+		chain.messages = append(chain.messages, &Message{Source: 2, Destination: 1, DepositNonce: nonce, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}})
+	}
+	expected := &Message{Source: 2, Destination: 2, DepositNonce: 6, Type: GenericTransfer, Payload: &GenericTransferPayload{Metadata: []byte{1}}}
+	chain.messages = append(chain.messages, expected)
+
+	r := NewRelayer([]RelayedChain{stuck, chain}, &mockMessageStore{statuses: make(map[uint64]MessageStatus)})
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.Start(ctx, make(chan error, 1))
+
+	deadline := time.Now().Add(time.Second * 5)
+	for len(chain.writtenMessages()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for message to chain that is not stuck")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if written := chain.writtenMessages()[0]; written.DepositNonce != expected.DepositNonce {
+		t.Fatalf("unexpected written message %+v", written)
+	}
+
+	cancel()
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancelStop()
+	_ = r.Stop(stopCtx)
+}
+
+func TestWorkerConfigDefaults(t *testing.T) {
+	config := workerConfig(&workerChain{config: WorkerConfig{QueueSize: 10, OrderByNonce: true}})
+	if config.Concurrency != DefaultWorkerConfig.Concurrency || config.QueueSize != 10 || !config.OrderByNonce {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if config = workerConfig(&mockChain{}); config != DefaultWorkerConfig {
+		t.Fatalf("unexpected config %+v", config)
+	}
+}